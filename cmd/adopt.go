@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/server"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+var (
+	adoptOpts server.AdoptOptions
+)
+
+var adoptCmd = &cobra.Command{
+	Use:   "adopt",
+	Short: "Create records from the Unifi clients with a fixed IP address",
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := db.WithActor(cmd.Context(), db.Actor{Name: "cli:adopt"})
+
+		if !adoptOpts.DryRun {
+			defer lockDatabase(ctx).Unlock()
+		}
+
+		db, err := db.NewDatabase(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the database")
+		}
+
+		unifi, err := unifi.NewClient(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the unifi client")
+		}
+
+		result, err := server.Adopt(ctx, db, unifi, adoptOpts)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to adopt the clients")
+		}
+
+		created := 0
+		for _, entry := range result.Entries {
+			log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+				"mac":     entry.MAC,
+				"ip":      entry.IP,
+				"network": entry.Network,
+			})
+
+			switch entry.Action {
+			case server.AdoptActionCreate:
+				created++
+				if result.DryRun {
+					log.Infof("%s would be created", entry.Name)
+				} else {
+					log.Infof("%s has been created", entry.Name)
+				}
+
+			case server.AdoptActionExists:
+				log.Infof("%s already exists", entry.Name)
+
+			default:
+				log.Warningf("%s is skipped (%s): %s", entry.Name, entry.Action, entry.Reason)
+			}
+		}
+
+		if result.DryRun {
+			logrus.WithContext(ctx).Infof("%d records would be created", created)
+		} else {
+			logrus.WithContext(ctx).Infof("%d records have been created", created)
+		}
+	},
+}
+
+func init() {
+	adoptCmd.Flags().StringVar(&adoptOpts.Network, "network", "", "Only adopt the clients of this network")
+	adoptCmd.Flags().StringVar(&adoptOpts.Match, "match", "", "Only adopt the clients whose name matches this regular expression")
+	adoptCmd.Flags().StringSliceVar(&adoptOpts.MACs, "mac", nil, "Only adopt the clients with those MAC addresses")
+	adoptCmd.Flags().BoolVar(&adoptOpts.DryRun, "dry-run", false, "Only report what would be done")
+	rootCmd.AddCommand(adoptCmd)
+}
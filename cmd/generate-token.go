@@ -7,27 +7,39 @@ import (
 	"github.com/rclsilver-org/usg-dns-api/db"
 )
 
+var (
+	generateOverrideToken bool
+)
+
 var generateTokenCmd = &cobra.Command{
 	Use:   "generate-token",
-	Short: "Generate the master token",
+	Short: "Generate the master token, or the protection override token",
 	Run: func(cmd *cobra.Command, args []string) {
 		ctx := cmd.Context()
 
+		defer lockDatabase(ctx).Unlock()
+
 		db, err := db.NewDatabase(ctx)
 		if err != nil {
 			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the database")
 		}
 
-		token := db.GenerateMasterToken()
+		name, token := "master", ""
+		if generateOverrideToken {
+			name, token = "protection override", db.GenerateOverrideToken()
+		} else {
+			token = db.GenerateMasterToken()
+		}
 
 		if err := db.Save(); err != nil {
 			logrus.WithContext(ctx).WithError(err).Fatal("unable to write the database")
 		}
 
-		logrus.WithContext(ctx).Infof("a new master token has been generated: %s", token)
+		logrus.WithContext(ctx).Infof("a new %s token has been generated: %s", name, token)
 	},
 }
 
 func init() {
+	generateTokenCmd.Flags().BoolVar(&generateOverrideToken, "override", false, "Generate the token allowed to change the protected records")
 	rootCmd.AddCommand(generateTokenCmd)
 }
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/importer"
+)
+
+var (
+	importFormat string
+	importOrigin string
+	importOpts   importer.Options
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create or update records from a hosts, CSV, BIND zone or Pi-hole file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ctx := db.WithActor(cmd.Context(), db.Actor{Name: "cli:import"})
+
+		if !importOpts.DryRun {
+			defer lockDatabase(ctx).Unlock()
+		}
+
+		f, err := os.Open(args[0])
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to open the file")
+		}
+		defer f.Close()
+
+		entries, err := importer.Parse(f, importFormat, importOrigin)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatalf("unable to parse the file %s", args[0])
+		}
+
+		db, err := db.NewDatabase(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the database")
+		}
+
+		result, err := importer.Import(ctx, db, entries, importOpts)
+		if result != nil {
+			for _, entry := range result.Entries {
+				log := logrus.WithContext(ctx).WithFields(logrus.Fields{
+					"target": entry.Target,
+				})
+				if entry.Line > 0 {
+					log = log.WithField("line", entry.Line)
+				}
+
+				switch entry.Action {
+				case importer.ActionCreate, importer.ActionDelete:
+					log.Infof("%s: %s", entry.Action, entry.Name)
+
+				case importer.ActionUpdate:
+					log.Infof("%s: %s (previous target: %s)", entry.Action, entry.Name, entry.Previous)
+
+				case importer.ActionUnchanged:
+					log.Debugf("%s: %s", entry.Action, entry.Name)
+
+				default:
+					log.Warningf("%s: %s (%s)", entry.Action, entry.Name, entry.Reason)
+				}
+			}
+		}
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to import the records")
+		}
+
+		summary := fmt.Sprintf("%d created, %d updated, %d deleted, %d unchanged, %d conflicts, %d invalid",
+			result.Count(importer.ActionCreate),
+			result.Count(importer.ActionUpdate),
+			result.Count(importer.ActionDelete),
+			result.Count(importer.ActionUnchanged),
+			result.Count(importer.ActionConflict),
+			result.Count(importer.ActionInvalid),
+		)
+		if result.DryRun {
+			logrus.WithContext(ctx).Infof("dry-run: %s", summary)
+		} else {
+			logrus.WithContext(ctx).Info(summary)
+		}
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importFormat, "format", importer.FormatHosts, fmt.Sprintf("Format of the file (%s)", strings.Join(importer.Formats, ", ")))
+	importCmd.Flags().StringVar(&importOrigin, "origin", "", "Origin of the relative names of a BIND zone without $ORIGIN")
+	importCmd.Flags().StringVar(&importOpts.OnConflict, "on-conflict", importer.OnConflictSkip, "Policy applied when a record exists with another target (skip, overwrite, fail)")
+	importCmd.Flags().BoolVar(&importOpts.Replace, "replace", false, "Delete the records which are not part of the file")
+	importCmd.Flags().BoolVar(&importOpts.DryRun, "dry-run", false, "Only report what would be done")
+	rootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+// lockDatabase stops the commands writing the database while the server runs,
+// as the server would overwrite their changes.
+func lockDatabase(ctx context.Context) *db.Lock {
+	lock, err := db.LockDatabase()
+	if err == db.ErrLocked {
+		logrus.WithContext(ctx).Fatal("the database is locked, the server is probably running: please stop it first or use the API")
+	} else if err != nil {
+		logrus.WithContext(ctx).WithError(err).Fatal("unable to lock the database")
+	}
+	return lock
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/inventory"
 	"github.com/rclsilver-org/usg-dns-api/pkg/pid"
 	"github.com/rclsilver-org/usg-dns-api/server"
 	"github.com/rclsilver-org/usg-dns-api/unifi"
@@ -45,6 +46,8 @@ var serverCmd = &cobra.Command{
 			cancel()
 		}()
 
+		defer lockDatabase(ctx).Unlock()
+
 		db, err := db.NewDatabase(ctx)
 		if err != nil {
 			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the database")
@@ -54,17 +57,23 @@ var serverCmd = &cobra.Command{
 			logrus.WithContext(ctx).Fatal("no master token generated. please use the 'generate-token' command to generate a new one")
 		}
 
-		unifi, err := unifi.NewClient(ctx)
-		if err != nil {
+		unifiClient, err := unifi.NewClient(ctx)
+		if err == unifi.ErrNotConfigured {
+			logrus.WithContext(ctx).Info("no unifi-controller configured, running in standalone mode")
+		} else if err != nil {
 			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the unifi client")
+		} else if err := unifiClient.Login(ctx); err != nil {
+			logrus.WithContext(ctx).WithError(err).Warning("unable to login to the unifi-controller, the last known state will be used")
+		} else {
+			logrus.WithContext(ctx).Debug("successfully connected to unifi-controller")
 		}
 
-		if err := unifi.Login(ctx); err != nil {
-			logrus.WithContext(ctx).WithError(err).Fatal("unable to login to the unifi-controller")
+		sources, err := inventory.NewSources(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the inventory sources")
 		}
-		logrus.WithContext(ctx).Debug("successfully connected to unifi-controller")
 
-		s, err := server.NewServer(ctx, db, unifi, server.WithVerbose(verbose), server.WithTitle("usg-dns-api"), server.WithVersion(version.VersionFull()))
+		s, err := server.NewServer(ctx, db, unifiClient, server.WithVerbose(verbose), server.WithTitle("usg-dns-api"), server.WithVersion(version.VersionFull()), server.WithSources(sources...))
 		if err != nil {
 			logrus.WithContext(ctx).WithError(err).Fatal("unable to initialize the server")
 		}
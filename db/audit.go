@@ -0,0 +1,232 @@
+package db
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AuditOperationCreate  = "create"
+	AuditOperationUpdate  = "update"
+	AuditOperationDelete  = "delete"
+	AuditOperationRevert  = "revert"
+	AuditOperationRestore = "restore"
+)
+
+type actorKey struct{}
+
+type protectionOverrideKey struct{}
+
+type Actor struct {
+	Name          string
+	RemoteAddress string
+	RequestID     string
+}
+
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) (Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(Actor)
+	return actor, ok
+}
+
+func WithProtectionOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, protectionOverrideKey{}, true)
+}
+
+func protectionOverridden(ctx context.Context) bool {
+	overridden, _ := ctx.Value(protectionOverrideKey{}).(bool)
+	return overridden
+}
+
+func actorName(ctx context.Context) string {
+	if actor, ok := ActorFromContext(ctx); ok && actor.Name != "" {
+		return actor.Name
+	}
+	return "unknown"
+}
+
+type AuditEntry struct {
+	Timestamp     time.Time `json:"timestamp"`
+	Actor         string    `json:"actor"`
+	RemoteAddress string    `json:"remote_address,omitempty"`
+	RequestID     string    `json:"request_id,omitempty"`
+	Operation     string    `json:"operation"`
+	RecordID      string    `json:"record_id"`
+	Before        *Record   `json:"before,omitempty"`
+	After         *Record   `json:"after,omitempty"`
+}
+
+type AuditFilter struct {
+	Since    time.Time
+	Until    time.Time
+	RecordID string
+	// Limit keeps the most recent entries only, 0 for all of them.
+	Limit int
+}
+
+func (f AuditFilter) match(entry AuditEntry) bool {
+	if !f.Since.IsZero() && entry.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && entry.Timestamp.After(f.Until) {
+		return false
+	}
+	if f.RecordID != "" && entry.RecordID != f.RecordID {
+		return false
+	}
+	return true
+}
+
+// auditLog is a JSON lines file, rotated when it reaches its maximum size.
+type auditLog struct {
+	mut      sync.Mutex
+	path     string
+	maxSize  int64
+	maxFiles int
+}
+
+func (l *auditLog) rotatedPath(i int) string {
+	if i == 0 {
+		return l.path
+	}
+	return fmt.Sprintf("%s.%d", l.path, i)
+}
+
+func (l *auditLog) rotate() error {
+	for i := l.maxFiles - 1; i > 0; i-- {
+		if err := os.Rename(l.rotatedPath(i-1), l.rotatedPath(i)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to rotate the audit log: %w", err)
+		}
+	}
+
+	if l.maxFiles <= 1 {
+		if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("unable to rotate the audit log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (l *auditLog) append(entry AuditEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if info, err := os.Stat(l.path); err == nil && l.maxSize > 0 && info.Size()+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("unable to open the audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("unable to write the audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (l *auditLog) read(filter AuditFilter) ([]AuditEntry, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	entries := []AuditEntry{}
+
+	for i := l.maxFiles - 1; i >= 0; i-- {
+		f, err := os.Open(l.rotatedPath(i))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("unable to open the audit log: %w", err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var entry AuditEntry
+			if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+				f.Close()
+				return nil, fmt.Errorf("unable to parse the audit log %s: %w", l.rotatedPath(i), err)
+			}
+			if filter.match(entry) {
+				entries = append(entries, entry)
+				if filter.Limit > 0 && len(entries) > filter.Limit {
+					entries = entries[1:]
+				}
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the audit log: %w", err)
+		}
+	}
+
+	return entries, nil
+}
+
+// logAudit only logs the failures, as the change is already saved.
+func (db *Database) logAudit(ctx context.Context, operation string, before, after *Record) {
+	if db.auditLog == nil {
+		return
+	}
+
+	if err := db.audit(ctx, operation, before, after); err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("unable to write the audit log")
+	}
+}
+
+func (db *Database) audit(ctx context.Context, operation string, before, after *Record) error {
+	entry := AuditEntry{
+		Timestamp: time.Now(),
+		Operation: operation,
+		Before:    before,
+		After:     after,
+	}
+
+	if after != nil {
+		entry.RecordID = after.ID
+	} else if before != nil {
+		entry.RecordID = before.ID
+	}
+
+	entry.Actor = actorName(ctx)
+	if actor, ok := ActorFromContext(ctx); ok {
+		entry.RemoteAddress = actor.RemoteAddress
+		entry.RequestID = actor.RequestID
+	}
+
+	return db.auditLog.append(entry)
+}
+
+// GetAuditEntries does not block the changes, the audit log has its own lock.
+func (db *Database) GetAuditEntries(filter AuditFilter) ([]AuditEntry, error) {
+	if db.auditLog == nil {
+		return []AuditEntry{}, nil
+	}
+
+	return db.auditLog.read(filter)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditLogRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.audit.log")
+	db := &Database{
+		cfg:      &config{Path: filepath.Join(filepath.Dir(path), "db.json")},
+		auditLog: &auditLog{path: path, maxSize: 400, maxFiles: 2},
+	}
+	ctx := WithActor(context.Background(), Actor{Name: "test"})
+
+	ids := []string{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		record, err := db.AddRecord(ctx, name, "192.168.1.1")
+		if err != nil {
+			t.Fatalf("AddRecord() error = %v", err)
+		}
+		ids = append(ids, record.ID)
+	}
+
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("the audit log has not been rotated: %v", err)
+	}
+	if _, err := os.Stat(path + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("too many rotated files: %v", err)
+	}
+
+	entries, err := db.GetAuditEntries(AuditFilter{})
+	if err != nil {
+		t.Fatalf("GetAuditEntries() error = %v", err)
+	}
+	if len(entries) == 0 || len(entries) >= len(ids) {
+		t.Fatalf("GetAuditEntries() returned %d entries", len(entries))
+	}
+	if last := entries[len(entries)-1]; last.RecordID != ids[len(ids)-1] || last.Actor != "test" || last.Operation != AuditOperationCreate {
+		t.Errorf("GetAuditEntries() last entry = %+v", last)
+	}
+
+	entries, err = db.GetAuditEntries(AuditFilter{RecordID: ids[len(ids)-1]})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("GetAuditEntries() = %v, %v", entries, err)
+	}
+
+	entries, err = db.GetAuditEntries(AuditFilter{Limit: 1})
+	if err != nil || len(entries) != 1 || entries[0].RecordID != ids[len(ids)-1] {
+		t.Errorf("GetAuditEntries() = %v, %v", entries, err)
+	}
+}
@@ -0,0 +1,129 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/juju/errors"
+)
+
+const (
+	BatchOperationCreate = "create"
+	BatchOperationUpdate = "update"
+	BatchOperationDelete = "delete"
+)
+
+type BatchOperation struct {
+	Operation string
+	ID        string
+	Name      string
+	Target    string
+	Version   int
+	Options   []RecordOption
+}
+
+type BatchResult struct {
+	Record Record
+	Err    error
+}
+
+func (op BatchOperation) validate() error {
+	switch op.Operation {
+	case BatchOperationCreate:
+		return newRecord(op.Name, op.Target, op.Options...).validate()
+
+	case BatchOperationUpdate:
+		if err := validateID(op.ID); err != nil {
+			return err
+		}
+		return newRecord(op.Name, op.Target, op.Options...).validate()
+
+	case BatchOperationDelete:
+		return validateID(op.ID)
+	}
+
+	return errors.NewBadRequest(nil, fmt.Sprintf("invalid operation %q", op.Operation))
+}
+
+// ApplyBatch applies the operations as a whole: when one of them fails, none is applied.
+func (db *Database) ApplyBatch(ctx context.Context, operations []BatchOperation) ([]BatchResult, error) {
+	results := make([]BatchResult, len(operations))
+
+	// validate all the operations before changing anything
+	var failed error
+	for i, op := range operations {
+		if err := op.validate(); err != nil {
+			results[i].Err = err
+			if failed == nil {
+				failed = err
+			}
+		}
+	}
+	if failed != nil {
+		return results, failed
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	previous := slices.Clone(db.data.Records)
+	previousHistory := maps.Clone(db.data.History)
+	previousTombstones := slices.Clone(db.data.Tombstones)
+
+	rollback := func() {
+		db.data.Records = previous
+		db.data.History = previousHistory
+		db.data.Tombstones = previousTombstones
+	}
+
+	befores := make([]Record, len(operations))
+	for i, op := range operations {
+		var err error
+
+		switch op.Operation {
+		case BatchOperationCreate:
+			results[i].Record, err = db.addRecord(ctx, newRecord(op.Name, op.Target, op.Options...))
+
+		case BatchOperationUpdate:
+			befores[i], results[i].Record, err = db.updateRecord(ctx, op.ID, op.Version, func(r *Record) {
+				r.Name = op.Name
+				r.Target = op.Target
+				for _, opt := range op.Options {
+					opt(r)
+				}
+			})
+
+		case BatchOperationDelete:
+			befores[i], err = db.deleteRecord(ctx, op.ID, op.Version)
+			results[i].Record = befores[i]
+		}
+
+		if err != nil {
+			rollback()
+			results[i].Err = err
+			return results, err
+		}
+	}
+
+	if err := db.save(); err != nil {
+		rollback()
+		return results, err
+	}
+
+	for i, op := range operations {
+		switch op.Operation {
+		case BatchOperationCreate:
+			db.logAudit(ctx, AuditOperationCreate, nil, &results[i].Record)
+
+		case BatchOperationUpdate:
+			db.logAudit(ctx, AuditOperationUpdate, &befores[i], &results[i].Record)
+
+		case BatchOperationDelete:
+			db.logAudit(ctx, AuditOperationDelete, &befores[i], nil)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApplyBatch(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json"), TombstoneRetention: time.Hour}}
+	ctx := context.Background()
+
+	nas, err := db.AddRecord(ctx, "nas", "192.168.1.10")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	// the last operation fails: none of them is applied
+	results, err := db.ApplyBatch(ctx, []BatchOperation{
+		{Operation: BatchOperationCreate, Name: "printer", Target: "192.168.1.20"},
+		{Operation: BatchOperationUpdate, ID: nas.ID, Name: "nas", Target: "192.168.1.11"},
+		{Operation: BatchOperationCreate, Name: "nas", Target: "192.168.1.12"},
+	})
+	if err != ErrAlreadyExists {
+		t.Fatalf("ApplyBatch() error = %v, want %v", err, ErrAlreadyExists)
+	}
+	if results[2].Err != ErrAlreadyExists {
+		t.Errorf("ApplyBatch() results[2].Err = %v, want %v", results[2].Err, ErrAlreadyExists)
+	}
+	if records := db.GetRecords(); len(records) != 1 || !reflect.DeepEqual(records[0], nas) {
+		t.Errorf("GetRecords() = %+v, want only %+v", records, nas)
+	}
+	if history, _ := db.GetRecordHistory(nas.ID); len(history) != 1 {
+		t.Errorf("GetRecordHistory() = %+v, want the creation only", history)
+	}
+
+	results, err = db.ApplyBatch(ctx, []BatchOperation{
+		{Operation: BatchOperationCreate, Name: "printer", Target: "192.168.1.20"},
+		{Operation: BatchOperationUpdate, ID: nas.ID, Name: "nas", Target: "192.168.1.11", Version: 1},
+		{Operation: BatchOperationDelete, ID: nas.ID, Version: 2},
+	})
+	if err != nil {
+		t.Fatalf("ApplyBatch() error = %v", err)
+	}
+	if results[1].Record.Target != "192.168.1.11" || results[1].Record.Version != 2 {
+		t.Errorf("ApplyBatch() results[1].Record = %+v", results[1].Record)
+	}
+	if records := db.GetRecords(); len(records) != 1 || records[0].Name != "printer" {
+		t.Errorf("GetRecords() = %+v, want only the printer", records)
+	}
+}
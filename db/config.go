@@ -2,12 +2,28 @@ package db
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/ovh/configstore"
+
+	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
 )
 
 const (
 	keyPath = "DB_PATH"
+
+	keyAuditLogPath     = "AUDIT_LOG_PATH"
+	keyAuditLogMaxSize  = "AUDIT_LOG_MAX_SIZE_MB"
+	keyAuditLogMaxFiles = "AUDIT_LOG_MAX_FILES"
+
+	keyTombstoneRetention = "TOMBSTONE_RETENTION"
+
+	defaultAuditLogMaxSize  = 10
+	defaultAuditLogMaxFiles = 5
+
+	defaultTombstoneRetention = 30 * 24 * time.Hour
 )
 
 var (
@@ -16,6 +32,12 @@ var (
 
 type config struct {
 	Path string
+
+	AuditLogPath     string
+	AuditLogMaxSize  int64
+	AuditLogMaxFiles int
+
+	TombstoneRetention time.Duration
 }
 
 func loadConfig() (*config, error) {
@@ -31,5 +53,50 @@ func loadConfig() (*config, error) {
 		cfg.Path = path
 	}
 
+	auditLogPath, err := configstore.GetItemValue(keyAuditLogPath)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the audit log path: %w", err)
+		}
+		// store the audit log next to the database
+		cfg.AuditLogPath = strings.TrimSuffix(cfg.Path, filepath.Ext(cfg.Path)) + ".audit.log"
+	} else {
+		cfg.AuditLogPath = auditLogPath
+	}
+
+	auditLogMaxSize, err := configstore.GetItemValueInt(keyAuditLogMaxSize)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the audit log max size: %w", err)
+		}
+		cfg.AuditLogMaxSize = defaultAuditLogMaxSize
+	} else {
+		cfg.AuditLogMaxSize = auditLogMaxSize
+	}
+
+	auditLogMaxFiles, err := configstore.GetItemValueInt(keyAuditLogMaxFiles)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the audit log max files: %w", err)
+		}
+		cfg.AuditLogMaxFiles = defaultAuditLogMaxFiles
+	} else {
+		cfg.AuditLogMaxFiles = int(auditLogMaxFiles)
+	}
+
+	tombstoneRetention, err := configstore.GetItemValue(keyTombstoneRetention)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the tombstone retention: %w", err)
+		}
+		cfg.TombstoneRetention = defaultTombstoneRetention
+	} else {
+		retention, err := utils.ParseDuration(tombstoneRetention)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tombstone retention: %w", err)
+		}
+		cfg.TombstoneRetention = retention
+	}
+
 	return &cfg, nil
 }
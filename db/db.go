@@ -5,7 +5,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/google/uuid"
@@ -16,18 +19,29 @@ import (
 )
 
 var (
-	ErrAlreadyExists = errors.New("resource already exists")
-	ErrNotFound      = errors.New("resource not found")
+	ErrAlreadyExists   = errors.New("resource already exists")
+	ErrNotFound        = errors.New("resource not found")
+	ErrManaged         = errors.New("resource managed by a declarative source")
+	ErrVersionMismatch = errors.New("resource version mismatch")
+	ErrProtected       = errors.New("resource protected")
 )
 
 type Database struct {
 	cfg *config
 	mut sync.Mutex
 
+	auditLog *auditLog
+
 	data struct {
-		MasterToken string `json:"master-token"`
+		SchemaVersion int `json:"schema-version"`
+
+		MasterToken   string `json:"master-token"`
+		OverrideToken string `json:"override-token,omitempty"`
 
 		Records []Record `json:"records"`
+
+		History    map[string][]RecordVersion `json:"history,omitempty"`
+		Tombstones []Tombstone                `json:"tombstones,omitempty"`
 	}
 }
 
@@ -42,6 +56,13 @@ func NewDatabase(ctx context.Context) (*Database, error) {
 	db := &Database{}
 	db.data.Records = make([]Record, 0)
 
+	db.cfg = cfg
+	db.auditLog = &auditLog{
+		path:     cfg.AuditLogPath,
+		maxSize:  cfg.AuditLogMaxSize * 1024 * 1024,
+		maxFiles: cfg.AuditLogMaxFiles,
+	}
+
 	f, err := os.Open(cfg.Path)
 	if err == nil {
 		defer f.Close()
@@ -54,12 +75,22 @@ func NewDatabase(ctx context.Context) (*Database, error) {
 		if err := json.Unmarshal(data, &db.data); err != nil {
 			return nil, fmt.Errorf("unable to unmarshal the data: %w", err)
 		}
-	} else if !os.IsNotExist(err) {
+
+		migrated, err := db.migrate(ctx, data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to upgrade the database: %w", err)
+		}
+		if migrated {
+			if err := db.save(); err != nil {
+				return nil, fmt.Errorf("unable to save the upgraded database: %w", err)
+			}
+		}
+	} else if os.IsNotExist(err) {
+		db.data.SchemaVersion = schemaVersion
+	} else {
 		return nil, fmt.Errorf("unable to load the database: %w", err)
 	}
 
-	db.cfg = cfg
-
 	return db, nil
 }
 
@@ -82,6 +113,25 @@ func (db *Database) GetMasterToken() string {
 	return db.data.MasterToken
 }
 
+func (db *Database) GenerateOverrideToken() string {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	token := uuid.NewString()
+	hash := utils.StringHash(token)
+
+	db.data.OverrideToken = hash
+
+	return token
+}
+
+func (db *Database) GetOverrideToken() string {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	return db.data.OverrideToken
+}
+
 func (db *Database) GetRecord(id string) (Record, error) {
 	if err := validateID(id); err != nil {
 		return Record{}, err
@@ -99,21 +149,44 @@ func (db *Database) GetRecord(id string) (Record, error) {
 	return Record{}, ErrNotFound
 }
 
-func (db *Database) AddRecord(name, target string) (Record, error) {
-	if err := validateName(name); err != nil {
+func (db *Database) AddRecord(ctx context.Context, name, target string, opts ...RecordOption) (Record, error) {
+	r := newRecord(name, target, opts...)
+	if err := r.validate(); err != nil {
 		return Record{}, err
 	}
 
-	if err := validateTarget(target); err != nil {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	r, err := db.addRecord(ctx, r)
+	if err != nil {
 		return Record{}, err
 	}
 
-	db.mut.Lock()
-	defer db.mut.Unlock()
+	if err := db.save(); err != nil {
+		return Record{}, err
+	}
+	db.logAudit(ctx, AuditOperationCreate, nil, &r)
 
+	return r, nil
+}
+
+func newRecord(name, target string, opts ...RecordOption) Record {
 	r := Record{
-		Name:   name,
-		Target: target,
+		Name:    name,
+		Target:  target,
+		Enabled: true,
+	}
+	for _, opt := range opts {
+		opt(&r)
+	}
+	return r
+}
+
+// addRecord is called with the lock held, the caller saves the database.
+func (db *Database) addRecord(ctx context.Context, r Record) (Record, error) {
+	if err := r.validate(); err != nil {
+		return Record{}, err
 	}
 
 	for {
@@ -121,7 +194,7 @@ func (db *Database) AddRecord(name, target string) (Record, error) {
 		found := false
 
 		for _, record := range db.data.Records {
-			if record.Name == r.Name {
+			if strings.EqualFold(record.Name, r.Name) {
 				return Record{}, ErrAlreadyExists
 			}
 
@@ -136,54 +209,180 @@ func (db *Database) AddRecord(name, target string) (Record, error) {
 		}
 	}
 
+	db.recordChange(ctx, AuditOperationCreate, nil, &r)
 	db.data.Records = append(db.data.Records, r)
 
-	if err := db.save(); err != nil {
-		return Record{}, err
-	}
-
 	return r, nil
 }
 
-func (db *Database) UpdateRecord(id, name, target string) (Record, error) {
+// UpdateRecord only updates the record at this version, unless it is 0.
+func (db *Database) UpdateRecord(ctx context.Context, id, name, target string, version int, opts ...RecordOption) (Record, error) {
 	if err := validateID(id); err != nil {
 		return Record{}, err
 	}
 
-	if err := validateName(name); err != nil {
+	if err := newRecord(name, target, opts...).validate(); err != nil {
 		return Record{}, err
 	}
 
-	if err := validateTarget(target); err != nil {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	before, after, err := db.updateRecord(ctx, id, version, func(r *Record) {
+		r.Name = name
+		r.Target = target
+		for _, opt := range opts {
+			opt(r)
+		}
+	})
+	if err != nil {
+		return Record{}, err
+	}
+
+	if err := db.save(); err != nil {
+		return Record{}, err
+	}
+	db.logAudit(ctx, AuditOperationUpdate, &before, &after)
+
+	return after, nil
+}
+
+// RecordPatch is a partial update of a record, the nil fields are unchanged.
+type RecordPatch struct {
+	Name   *string
+	Target *string
+
+	// the nil values remove the labels, ClearLabels removes all of them first
+	Labels      map[string]*string
+	ClearLabels bool
+
+	Description *string
+	Enabled     *bool
+	Protected   *bool
+}
+
+func (p RecordPatch) apply(r *Record) {
+	if p.Name != nil {
+		r.Name = *p.Name
+	}
+	if p.Target != nil {
+		r.Target = *p.Target
+	}
+
+	if p.ClearLabels {
+		r.Labels = nil
+	}
+	if len(p.Labels) > 0 {
+		labels := maps.Clone(r.Labels)
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for key, value := range p.Labels {
+			if value == nil {
+				delete(labels, key)
+			} else {
+				labels[key] = *value
+			}
+		}
+		r.Labels = nil
+		if len(labels) > 0 {
+			r.Labels = labels
+		}
+	}
+
+	if p.Description != nil {
+		r.Description = *p.Description
+	}
+	if p.Enabled != nil {
+		r.Enabled = *p.Enabled
+	}
+	if p.Protected != nil {
+		r.Protected = *p.Protected
+	}
+}
+
+func (db *Database) PatchRecord(ctx context.Context, id string, patch RecordPatch, version int) (Record, error) {
+	if err := validateID(id); err != nil {
 		return Record{}, err
 	}
 
 	db.mut.Lock()
 	defer db.mut.Unlock()
 
+	for _, record := range db.data.Records {
+		if record.ID != id {
+			continue
+		}
+
+		patched := record
+		patch.apply(&patched)
+		if err := patched.validate(); err != nil {
+			return Record{}, err
+		}
+
+		if patched.sameContent(record) && record.ManagedBy == "" {
+			if version != 0 && record.Version != version {
+				return Record{}, ErrVersionMismatch
+			}
+			return record, nil
+		}
+
+		before, after, err := db.updateRecord(ctx, id, version, patch.apply)
+		if err != nil {
+			return Record{}, err
+		}
+
+		if err := db.save(); err != nil {
+			return Record{}, err
+		}
+		db.logAudit(ctx, AuditOperationUpdate, &before, &after)
+
+		return after, nil
+	}
+
+	return Record{}, ErrNotFound
+}
+
+// updateRecord returns the record before and after the change.
+func (db *Database) updateRecord(ctx context.Context, id string, version int, change func(*Record)) (Record, Record, error) {
 	for i, record := range db.data.Records {
 		if record.ID == id {
-			for _, rec := range db.data.Records {
-				if rec.Name == name && rec.ID != id {
-					return Record{}, ErrAlreadyExists
-				}
+			if record.ManagedBy != "" {
+				return Record{}, Record{}, ErrManaged
 			}
 
-			db.data.Records[i].Name = name
-			db.data.Records[i].Target = target
+			if record.Protected && !protectionOverridden(ctx) {
+				return Record{}, Record{}, ErrProtected
+			}
 
-			if err := db.save(); err != nil {
-				return Record{}, err
+			if version != 0 && record.Version != version {
+				return Record{}, Record{}, ErrVersionMismatch
+			}
+
+			updated := record
+			updated.Labels = maps.Clone(record.Labels)
+			change(&updated)
+			if err := updated.validate(); err != nil {
+				return Record{}, Record{}, err
 			}
 
-			return db.data.Records[i], nil
+			for _, rec := range db.data.Records {
+				if strings.EqualFold(rec.Name, updated.Name) && rec.ID != id {
+					return Record{}, Record{}, ErrAlreadyExists
+				}
+			}
+
+			db.data.Records[i] = updated
+			db.recordChange(ctx, AuditOperationUpdate, &record, &db.data.Records[i])
+
+			return record, db.data.Records[i], nil
 		}
 	}
 
-	return Record{}, ErrNotFound
+	return Record{}, Record{}, ErrNotFound
 }
 
-func (db *Database) DeleteRecord(id string) error {
+func (db *Database) DeleteRecord(ctx context.Context, id string, version int) error {
 	if err := validateID(id); err != nil {
 		return err
 	}
@@ -191,19 +390,95 @@ func (db *Database) DeleteRecord(id string) error {
 	db.mut.Lock()
 	defer db.mut.Unlock()
 
+	record, err := db.deleteRecord(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	if err := db.save(); err != nil {
+		return err
+	}
+	db.logAudit(ctx, AuditOperationDelete, &record, nil)
+
+	return nil
+}
+
+// DeleteRecords deletes the records matching a positive selector as a whole,
+// except the managed ones and the protected ones without an override.
+func (db *Database) DeleteRecords(ctx context.Context, selector Selector) ([]Record, error) {
+	if selector.Empty() {
+		return nil, errors.NewBadRequest(nil, "the selector must not be empty")
+	}
+	if !selector.Positive() {
+		return nil, errors.NewBadRequest(nil, "the selector must have a key=value or key requirement")
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	deleted := []Record{}
+	for _, record := range db.data.Records {
+		if record.Protected && !protectionOverridden(ctx) {
+			continue
+		}
+		if record.ManagedBy == "" && selector.Matches(record.Labels) {
+			deleted = append(deleted, record)
+		}
+	}
+	if len(deleted) == 0 {
+		return deleted, nil
+	}
+
+	previous := slices.Clone(db.data.Records)
+	previousHistory := maps.Clone(db.data.History)
+	previousTombstones := slices.Clone(db.data.Tombstones)
+
+	for _, record := range deleted {
+		if _, err := db.deleteRecord(ctx, record.ID, 0); err != nil {
+			db.data.Records = previous
+			db.data.History = previousHistory
+			db.data.Tombstones = previousTombstones
+			return nil, err
+		}
+	}
+
+	if err := db.save(); err != nil {
+		db.data.Records = previous
+		db.data.History = previousHistory
+		db.data.Tombstones = previousTombstones
+		return nil, err
+	}
+
+	for i := range deleted {
+		db.logAudit(ctx, AuditOperationDelete, &deleted[i], nil)
+	}
+
+	return deleted, nil
+}
+
+func (db *Database) deleteRecord(ctx context.Context, id string, version int) (Record, error) {
 	for i, record := range db.data.Records {
 		if record.ID == id {
-			db.data.Records = append(db.data.Records[:i], db.data.Records[i+1:]...)
+			if record.ManagedBy != "" {
+				return Record{}, ErrManaged
+			}
 
-			if err := db.save(); err != nil {
-				return err
+			if record.Protected && !protectionOverridden(ctx) {
+				return Record{}, ErrProtected
+			}
+
+			if version != 0 && record.Version != version {
+				return Record{}, ErrVersionMismatch
 			}
 
-			return nil
+			db.data.Records = append(db.data.Records[:i], db.data.Records[i+1:]...)
+			db.recordChange(ctx, AuditOperationDelete, &record, nil)
+
+			return record, nil
 		}
 	}
 
-	return ErrNotFound
+	return Record{}, ErrNotFound
 }
 
 func (db *Database) GetRecords() []Record {
@@ -0,0 +1,78 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestProtectedRecord(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	ctx := context.Background()
+
+	record, err := db.AddRecord(ctx, "gateway", "192.168.1.1", WithProtected(true), WithLabels(map[string]string{"env": "lan"}))
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	if !record.Enabled {
+		t.Error("AddRecord() created a disabled record")
+	}
+
+	if _, err := db.UpdateRecord(ctx, record.ID, "gateway", "192.168.1.254", 0); err != ErrProtected {
+		t.Errorf("UpdateRecord() error = %v, want %v", err, ErrProtected)
+	}
+	if err := db.DeleteRecord(ctx, record.ID, 0); err != ErrProtected {
+		t.Errorf("DeleteRecord() error = %v, want %v", err, ErrProtected)
+	}
+	selector, _ := ParseSelector("env=lan")
+	if deleted, err := db.DeleteRecords(ctx, selector); err != nil || len(deleted) != 0 {
+		t.Errorf("DeleteRecords() = %+v, %v, want no deleted record", deleted, err)
+	}
+
+	override := WithProtectionOverride(ctx)
+	if _, err := db.UpdateRecord(override, record.ID, "gateway", "192.168.1.254", 0); err != nil {
+		t.Errorf("UpdateRecord() with override error = %v", err)
+	}
+	if err := db.DeleteRecord(override, record.ID, 0); err != nil {
+		t.Errorf("DeleteRecord() with override error = %v", err)
+	}
+}
+
+func TestDeleteRecords(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	ctx := context.Background()
+
+	for _, record := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "ci-1", labels: map[string]string{"env": "ci", "temporary": "true"}},
+		{name: "ci-2", labels: map[string]string{"env": "ci"}},
+		{name: "nas"},
+		{name: "printer", labels: map[string]string{"env": "prod"}},
+	} {
+		if _, err := db.AddRecord(ctx, record.name, "192.168.1.1", WithLabels(record.labels)); err != nil {
+			t.Fatalf("AddRecord() error = %v", err)
+		}
+	}
+
+	// the negative selectors also match the records without labels
+	for _, s := range []string{"", "!temporary", "env!=prod", "!temporary,env!=prod"} {
+		selector, err := ParseSelector(s)
+		if err != nil {
+			t.Fatalf("ParseSelector() error = %v", err)
+		}
+		if _, err := db.DeleteRecords(ctx, selector); err == nil {
+			t.Errorf("DeleteRecords(%q) accepted a selector without positive requirement", s)
+		}
+	}
+	if got := len(db.GetRecords()); got != 4 {
+		t.Fatalf("GetRecords() returned %d records, want 4", got)
+	}
+
+	selector, _ := ParseSelector("env=ci,!temporary")
+	deleted, err := db.DeleteRecords(ctx, selector)
+	if err != nil || len(deleted) != 1 || deleted[0].Name != "ci-2" {
+		t.Errorf("DeleteRecords() = %+v, %v, want ci-2", deleted, err)
+	}
+}
@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// versionInitial is the version of the records created before the history was kept.
+const versionInitial = "initial"
+
+type RecordVersion struct {
+	Version   int       `json:"version"`
+	Timestamp time.Time `json:"timestamp"`
+	Actor     string    `json:"actor"`
+	Operation string    `json:"operation"`
+	Name      string    `json:"name"`
+	Target    string    `json:"target"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	Protected   bool              `json:"protected,omitempty"`
+}
+
+func newRecordVersion(version int, operation string, record Record) RecordVersion {
+	return RecordVersion{
+		Version:     version,
+		Operation:   operation,
+		Name:        record.Name,
+		Target:      record.Target,
+		Labels:      maps.Clone(record.Labels),
+		Description: record.Description,
+		Enabled:     record.Enabled,
+		Protected:   record.Protected,
+	}
+}
+
+func (v RecordVersion) apply(record *Record) {
+	record.Name = v.Name
+	record.Target = v.Target
+	record.Labels = maps.Clone(v.Labels)
+	record.Description = v.Description
+	record.Enabled = v.Enabled
+	record.Protected = v.Protected
+}
+
+type Tombstone struct {
+	Record    Record    `json:"record"`
+	DeletedAt time.Time `json:"deleted_at"`
+	DeletedBy string    `json:"deleted_by"`
+}
+
+// recordChange must be called before the database is saved.
+func (db *Database) recordChange(ctx context.Context, operation string, before, after *Record) {
+	if db.data.History == nil {
+		db.data.History = map[string][]RecordVersion{}
+	}
+
+	now := time.Now()
+	actor := actorName(ctx)
+
+	record := after
+	if record == nil {
+		record = before
+	}
+
+	history := db.data.History[record.ID]
+
+	// the records created before the history start with their current state
+	if len(history) == 0 && before != nil {
+		history = append(history, newRecordVersion(before.Version, versionInitial, *before))
+	}
+
+	version := len(history) + 1
+	if after != nil {
+		after.Version = version
+		after.UpdatedAt = now
+		if operation == AuditOperationCreate {
+			after.CreatedAt = now
+			after.CreatedBy = actor
+		}
+	}
+
+	current := newRecordVersion(version, operation, *record)
+	current.Timestamp = now
+	current.Actor = actor
+	history = append(history, current)
+	db.data.History[record.ID] = history
+
+	if operation == AuditOperationDelete {
+		if db.cfg.TombstoneRetention > 0 {
+			db.data.Tombstones = append(db.data.Tombstones, Tombstone{
+				Record:    *before,
+				DeletedAt: now,
+				DeletedBy: actor,
+			})
+		} else {
+			delete(db.data.History, record.ID)
+		}
+	}
+
+	db.pruneTombstones(now)
+}
+
+func (db *Database) pruneTombstones(now time.Time) {
+	tombstones := db.data.Tombstones[:0]
+	for _, tombstone := range db.data.Tombstones {
+		if now.Sub(tombstone.DeletedAt) > db.cfg.TombstoneRetention {
+			delete(db.data.History, tombstone.Record.ID)
+			continue
+		}
+		tombstones = append(tombstones, tombstone)
+	}
+	db.data.Tombstones = tombstones
+}
+
+func (db *Database) findTombstone(id string) int {
+	for i, tombstone := range db.data.Tombstones {
+		if tombstone.Record.ID == id && time.Since(tombstone.DeletedAt) <= db.cfg.TombstoneRetention {
+			return i
+		}
+	}
+	return -1
+}
+
+func (db *Database) GetRecordHistory(id string) ([]RecordVersion, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	history, ok := db.data.History[id]
+	if !ok {
+		for _, record := range db.data.Records {
+			if record.ID == id {
+				// the record has not been changed since the history is kept
+				return []RecordVersion{newRecordVersion(record.Version, versionInitial, record)}, nil
+			}
+		}
+		return nil, ErrNotFound
+	}
+
+	historyCopy := make([]RecordVersion, len(history))
+	copy(historyCopy, history)
+	return historyCopy, nil
+}
+
+func (db *Database) RevertRecord(ctx context.Context, id string, version int) (Record, error) {
+	if err := validateID(id); err != nil {
+		return Record{}, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	for i, record := range db.data.Records {
+		if record.ID != id {
+			continue
+		}
+
+		if record.ManagedBy != "" {
+			return Record{}, ErrManaged
+		}
+
+		if record.Protected && !protectionOverridden(ctx) {
+			return Record{}, ErrProtected
+		}
+
+		var target RecordVersion
+		for _, v := range db.data.History[id] {
+			if v.Version == version {
+				target = v
+				break
+			}
+		}
+		if target.Version == 0 {
+			return Record{}, errors.NewNotFound(nil, fmt.Sprintf("no version %d for this record", version))
+		}
+		if target.Operation == AuditOperationDelete {
+			return Record{}, errors.NewBadRequest(nil, "unable to revert to a deleted version")
+		}
+
+		for _, rec := range db.data.Records {
+			if strings.EqualFold(rec.Name, target.Name) && rec.ID != id {
+				return Record{}, ErrAlreadyExists
+			}
+		}
+
+		target.apply(&db.data.Records[i])
+		db.recordChange(ctx, AuditOperationRevert, &record, &db.data.Records[i])
+
+		if err := db.save(); err != nil {
+			return Record{}, err
+		}
+		db.logAudit(ctx, AuditOperationRevert, &record, &db.data.Records[i])
+
+		return db.data.Records[i], nil
+	}
+
+	return Record{}, ErrNotFound
+}
+
+func (db *Database) GetDeletedRecords() []Tombstone {
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	tombstones := []Tombstone{}
+	for _, tombstone := range db.data.Tombstones {
+		if time.Since(tombstone.DeletedAt) <= db.cfg.TombstoneRetention {
+			tombstones = append(tombstones, tombstone)
+		}
+	}
+	return tombstones
+}
+
+func (db *Database) RestoreRecord(ctx context.Context, id string) (Record, error) {
+	if err := validateID(id); err != nil {
+		return Record{}, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	i := db.findTombstone(id)
+	if i < 0 {
+		return Record{}, ErrNotFound
+	}
+	record := db.data.Tombstones[i].Record
+
+	if record.ManagedBy != "" {
+		return Record{}, ErrManaged
+	}
+
+	for _, rec := range db.data.Records {
+		if strings.EqualFold(rec.Name, record.Name) {
+			return Record{}, ErrAlreadyExists
+		}
+	}
+
+	db.data.Tombstones = append(db.data.Tombstones[:i], db.data.Tombstones[i+1:]...)
+	db.recordChange(ctx, AuditOperationRestore, nil, &record)
+	db.data.Records = append(db.data.Records, record)
+
+	if err := db.save(); err != nil {
+		return Record{}, err
+	}
+	db.logAudit(ctx, AuditOperationRestore, nil, &record)
+
+	return record, nil
+}
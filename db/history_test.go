@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecordHistory(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json"), TombstoneRetention: time.Hour}}
+	ctx := WithActor(context.Background(), Actor{Name: "test"})
+
+	record, err := db.AddRecord(ctx, "nas", "192.168.1.10")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	if _, err := db.UpdateRecord(ctx, record.ID, "storage", "192.168.1.11", 0); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+
+	reverted, err := db.RevertRecord(ctx, record.ID, 1)
+	if err != nil {
+		t.Fatalf("RevertRecord() error = %v", err)
+	}
+	if reverted.Name != "nas" || reverted.Target != "192.168.1.10" {
+		t.Errorf("RevertRecord() = %+v", reverted)
+	}
+
+	if err := db.DeleteRecord(ctx, record.ID, 0); err != nil {
+		t.Fatalf("DeleteRecord() error = %v", err)
+	}
+	if _, err := db.RevertRecord(ctx, record.ID, 1); err != ErrNotFound {
+		t.Errorf("RevertRecord() error = %v, want %v", err, ErrNotFound)
+	}
+	if tombstones := db.GetDeletedRecords(); len(tombstones) != 1 || tombstones[0].DeletedBy != "test" {
+		t.Errorf("GetDeletedRecords() = %+v", tombstones)
+	}
+
+	if _, err := db.RestoreRecord(ctx, record.ID); err != nil {
+		t.Fatalf("RestoreRecord() error = %v", err)
+	}
+	if len(db.GetDeletedRecords()) != 0 {
+		t.Error("GetDeletedRecords() still returns the restored record")
+	}
+
+	history, err := db.GetRecordHistory(record.ID)
+	if err != nil {
+		t.Fatalf("GetRecordHistory() error = %v", err)
+	}
+	operations := []string{AuditOperationCreate, AuditOperationUpdate, AuditOperationRevert, AuditOperationDelete, AuditOperationRestore}
+	if len(history) != len(operations) {
+		t.Fatalf("GetRecordHistory() returned %d versions, want %d", len(history), len(operations))
+	}
+	for i, version := range history {
+		if version.Version != i+1 || version.Operation != operations[i] {
+			t.Errorf("GetRecordHistory()[%d] = %+v, want version %d (%s)", i, version, i+1, operations[i])
+		}
+	}
+
+	// the tombstones expire at the end of the retention period
+	if err := db.DeleteRecord(ctx, record.ID, 0); err != nil {
+		t.Fatalf("DeleteRecord() error = %v", err)
+	}
+	db.pruneTombstones(time.Now().Add(2 * time.Hour))
+	if _, err := db.RestoreRecord(ctx, record.ID); err != ErrNotFound {
+		t.Errorf("RestoreRecord() error = %v, want %v", err, ErrNotFound)
+	}
+	if _, err := db.GetRecordHistory(record.ID); err != ErrNotFound {
+		t.Errorf("GetRecordHistory() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestRevertRecordAttributes(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json"), TombstoneRetention: time.Hour}}
+	ctx := context.Background()
+
+	record, err := db.AddRecord(ctx, "nas", "192.168.1.10", WithLabels(map[string]string{"env": "prod"}), WithDescription("storage"))
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	if _, err := db.UpdateRecord(ctx, record.ID, record.Name, record.Target, 0, WithLabels(map[string]string{"team": "net"}), WithDescription(""), WithEnabled(false)); err != nil {
+		t.Fatalf("UpdateRecord() error = %v", err)
+	}
+
+	history, err := db.GetRecordHistory(record.ID)
+	if err != nil {
+		t.Fatalf("GetRecordHistory() error = %v", err)
+	}
+	if len(history) != 2 || history[1].Enabled || history[1].Labels["team"] != "net" || reflect.DeepEqual(history[0], history[1]) {
+		t.Errorf("GetRecordHistory() = %+v, want the changed attributes", history)
+	}
+
+	reverted, err := db.RevertRecord(ctx, record.ID, 1)
+	if err != nil {
+		t.Fatalf("RevertRecord() error = %v", err)
+	}
+	if !reflect.DeepEqual(reverted.Labels, map[string]string{"env": "prod"}) || reverted.Description != "storage" || !reverted.Enabled || reverted.Protected {
+		t.Errorf("RevertRecord() = %+v, want the attributes of the version 1", reverted)
+	}
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/juju/errors"
+)
+
+var ErrLocked = errors.New("database locked by another process")
+
+type Lock struct {
+	f *os.File
+}
+
+func (l *Lock) Unlock() error {
+	return l.f.Close()
+}
+
+// LockDatabase is held by the server while it runs, and by the commands writing the database.
+func LockDatabase() (*Lock, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the configuration: %w", err)
+	}
+
+	return lockFile(cfg.Path + ".lock")
+}
+
+func lockFile(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("unable to lock the database: %w", err)
+	}
+
+	return &Lock{f: f}, nil
+}
@@ -0,0 +1,29 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json.lock")
+
+	lock, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+
+	if _, err := lockFile(path); err != ErrLocked {
+		t.Fatalf("lockFile() error = %v, want %v", err, ErrLocked)
+	}
+
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Unlock() error = %v", err)
+	}
+
+	lock, err = lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile() error = %v", err)
+	}
+	lock.Unlock()
+}
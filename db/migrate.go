@@ -0,0 +1,114 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// migrations[i] upgrades the schema i to the schema i+1.
+var migrations = []func(db *Database, raw []byte, now time.Time){
+	// the records start with the version 1
+	func(db *Database, raw []byte, now time.Time) {
+		for i := range db.data.Records {
+			if db.data.Records[i].Version == 0 {
+				db.data.Records[i].Version = 1
+			}
+		}
+	},
+
+	// the records get timestamps, from the history when it is known
+	func(db *Database, raw []byte, now time.Time) {
+		for i := range db.data.Records {
+			record := &db.data.Records[i]
+			record.CreatedAt = now
+			record.UpdatedAt = now
+			record.CreatedBy = actorName(context.Background())
+
+			for _, version := range db.data.History[record.ID] {
+				if version.Timestamp.IsZero() {
+					continue
+				}
+				if version.Operation == AuditOperationCreate {
+					record.CreatedAt = version.Timestamp
+					record.CreatedBy = version.Actor
+				}
+				record.UpdatedAt = version.Timestamp
+			}
+		}
+	},
+
+	// the records can be disabled, the existing ones are enabled
+	func(db *Database, raw []byte, now time.Time) {
+		for i := range db.data.Records {
+			db.data.Records[i].Enabled = true
+		}
+		for i := range db.data.Tombstones {
+			db.data.Tombstones[i].Record.Enabled = true
+		}
+	},
+
+	// the history keeps all the attributes, the older versions were enabled
+	func(db *Database, raw []byte, now time.Time) {
+		for _, versions := range db.data.History {
+			for i := range versions {
+				versions[i].Enabled = true
+			}
+		}
+	},
+
+	// the managed-by key of the records is renamed to managed_by
+	func(db *Database, raw []byte, now time.Time) {
+		type legacyRecord struct {
+			ID        string `json:"id"`
+			ManagedBy string `json:"managed-by"`
+		}
+		var legacy struct {
+			Records    []legacyRecord `json:"records"`
+			Tombstones []struct {
+				Record legacyRecord `json:"record"`
+			} `json:"tombstones"`
+		}
+		_ = json.Unmarshal(raw, &legacy)
+
+		managedBy := map[string]string{}
+		for _, record := range legacy.Records {
+			managedBy[record.ID] = record.ManagedBy
+		}
+		for _, tombstone := range legacy.Tombstones {
+			managedBy[tombstone.Record.ID] = tombstone.Record.ManagedBy
+		}
+
+		for i := range db.data.Records {
+			if db.data.Records[i].ManagedBy == "" {
+				db.data.Records[i].ManagedBy = managedBy[db.data.Records[i].ID]
+			}
+		}
+		for i := range db.data.Tombstones {
+			if db.data.Tombstones[i].Record.ManagedBy == "" {
+				db.data.Tombstones[i].Record.ManagedBy = managedBy[db.data.Tombstones[i].Record.ID]
+			}
+		}
+	},
+}
+
+var schemaVersion = len(migrations)
+
+func (db *Database) migrate(ctx context.Context, raw []byte) (bool, error) {
+	if db.data.SchemaVersion > schemaVersion {
+		return false, fmt.Errorf("unsupported schema version %d, the latest supported one is %d", db.data.SchemaVersion, schemaVersion)
+	}
+
+	migrated := db.data.SchemaVersion < schemaVersion
+	now := time.Now()
+
+	for ; db.data.SchemaVersion < schemaVersion; db.data.SchemaVersion++ {
+		logrus.WithContext(ctx).Infof("upgrading the database schema to the version %d", db.data.SchemaVersion+1)
+		migrations[db.data.SchemaVersion](db, raw, now)
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMigrate(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	updated := created.Add(time.Hour)
+
+	// a database written before the schema versions
+	data := `{
+  "master-token": "hash",
+  "records": [
+    {"id": "8a4b3c0e-8f3e-4f4b-9c54-0f4d5a1f2b3c", "name": "nas", "target": "192.168.1.10", "managed-by": "records-file"},
+    {"id": "0b7e1c9a-3f2d-4e5b-8a6c-1d2e3f4a5b6c", "name": "printer", "target": "192.168.1.20", "version": 2}
+  ],
+  "history": {
+    "0b7e1c9a-3f2d-4e5b-8a6c-1d2e3f4a5b6c": [
+      {"version": 1, "timestamp": "` + created.Format(time.RFC3339) + `", "actor": "admin", "operation": "create", "name": "printer", "target": "192.168.1.2"},
+      {"version": 2, "timestamp": "` + updated.Format(time.RFC3339) + `", "actor": "admin", "operation": "update", "name": "printer", "target": "192.168.1.20"}
+    ]
+  }
+}`
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	if err := json.Unmarshal([]byte(data), &db.data); err != nil {
+		t.Fatal(err)
+	}
+
+	migrated, err := db.migrate(context.Background(), []byte(data))
+	if err != nil || !migrated {
+		t.Fatalf("migrate() = %v, %v, want a migration", migrated, err)
+	}
+	if db.data.SchemaVersion != schemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", db.data.SchemaVersion, schemaVersion)
+	}
+
+	nas, printer := db.data.Records[0], db.data.Records[1]
+	if nas.Version != 1 || nas.CreatedAt.IsZero() || nas.CreatedBy != "unknown" || !nas.Enabled || nas.ManagedBy != "records-file" {
+		t.Errorf("migrated record without history = %+v", nas)
+	}
+	if printer.Version != 2 || !printer.CreatedAt.Equal(created) || !printer.UpdatedAt.Equal(updated) || printer.CreatedBy != "admin" {
+		t.Errorf("migrated record with history = %+v", printer)
+	}
+
+	for _, version := range db.data.History[printer.ID] {
+		if !version.Enabled {
+			t.Errorf("migrated version = %+v, want an enabled version", version)
+		}
+	}
+
+	if migrated, err := db.migrate(context.Background(), []byte(data)); err != nil || migrated {
+		t.Errorf("migrate() = %v, %v, want no migration", migrated, err)
+	}
+
+	db.data.SchemaVersion = schemaVersion + 1
+	if _, err := db.migrate(context.Background(), []byte(data)); err == nil {
+		t.Error("migrate() accepted a newer schema version")
+	}
+}
@@ -1,8 +1,75 @@
 package db
 
+import (
+	"maps"
+	"time"
+)
+
 type Record struct {
 	Base
 
 	Name   string `json:"name"`
 	Target string `json:"target"`
+
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Enabled     bool              `json:"enabled"`
+	Protected   bool              `json:"protected,omitempty"`
+
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	CreatedBy string    `json:"created_by"`
+	ManagedBy string    `json:"managed_by,omitempty"`
+}
+
+type RecordOption func(*Record)
+
+// WithLabels leaves the labels unchanged with a nil map.
+func WithLabels(labels map[string]string) RecordOption {
+	return func(r *Record) {
+		if labels == nil {
+			return
+		}
+		r.Labels = nil
+		if len(labels) > 0 {
+			r.Labels = maps.Clone(labels)
+		}
+	}
+}
+
+func WithDescription(description string) RecordOption {
+	return func(r *Record) {
+		r.Description = description
+	}
+}
+
+func WithEnabled(enabled bool) RecordOption {
+	return func(r *Record) {
+		r.Enabled = enabled
+	}
+}
+
+func WithProtected(protected bool) RecordOption {
+	return func(r *Record) {
+		r.Protected = protected
+	}
+}
+
+func (r Record) sameContent(o Record) bool {
+	return r.Name == o.Name && r.Target == o.Target && r.Description == o.Description && maps.Equal(r.Labels, o.Labels) &&
+		r.Enabled == o.Enabled && r.Protected == o.Protected
+}
+
+func (r Record) validate() error {
+	if err := validateName(r.Name); err != nil {
+		return err
+	}
+	if err := validateTarget(r.Target); err != nil {
+		return err
+	}
+	if err := validateLabels(r.Labels); err != nil {
+		return err
+	}
+	return validateDescription(r.Description)
 }
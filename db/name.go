@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"maps"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+// findRecordByName compares the names case-insensitively, with the lock held.
+func (db *Database) findRecordByName(name string) (int, error) {
+	found := -1
+	for i, record := range db.data.Records {
+		if strings.EqualFold(record.Name, name) {
+			if found >= 0 {
+				return -1, errors.NewAlreadyExists(nil, "several records match this name")
+			}
+			found = i
+		}
+	}
+
+	if found < 0 {
+		return -1, ErrNotFound
+	}
+	return found, nil
+}
+
+func (db *Database) GetRecordByName(name string) (Record, error) {
+	if err := validateName(name); err != nil {
+		return Record{}, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	i, err := db.findRecordByName(name)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return db.data.Records[i], nil
+}
+
+// PutRecordByName creates or replaces the record, and returns true when it has been created.
+func (db *Database) PutRecordByName(ctx context.Context, name, target string, version int, opts ...RecordOption) (Record, bool, error) {
+	if err := newRecord(name, target, opts...).validate(); err != nil {
+		return Record{}, false, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	i, err := db.findRecordByName(name)
+	if err == ErrNotFound {
+		if version != 0 {
+			return Record{}, false, ErrVersionMismatch
+		}
+
+		r, err := db.addRecord(ctx, newRecord(name, target, opts...))
+		if err != nil {
+			return Record{}, false, err
+		}
+
+		if err := db.save(); err != nil {
+			return Record{}, false, err
+		}
+		db.logAudit(ctx, AuditOperationCreate, nil, &r)
+
+		return r, true, nil
+	} else if err != nil {
+		return Record{}, false, err
+	}
+
+	record := db.data.Records[i]
+	if record.ManagedBy != "" {
+		return Record{}, false, ErrManaged
+	}
+
+	if version != 0 && record.Version != version {
+		return Record{}, false, ErrVersionMismatch
+	}
+
+	change := func(r *Record) {
+		r.Name = name
+		r.Target = target
+		for _, opt := range opts {
+			opt(r)
+		}
+	}
+
+	replaced := record
+	replaced.Labels = maps.Clone(record.Labels)
+	change(&replaced)
+	if replaced.sameContent(record) {
+		return record, false, nil
+	}
+
+	before, after, err := db.updateRecord(ctx, record.ID, version, change)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	if err := db.save(); err != nil {
+		return Record{}, false, err
+	}
+	db.logAudit(ctx, AuditOperationUpdate, &before, &after)
+
+	return after, false, nil
+}
+
+func (db *Database) DeleteRecordByName(ctx context.Context, name string, version int) (Record, error) {
+	if err := validateName(name); err != nil {
+		return Record{}, err
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	i, err := db.findRecordByName(name)
+	if err != nil {
+		return Record{}, err
+	}
+
+	record, err := db.deleteRecord(ctx, db.data.Records[i].ID, version)
+	if err != nil {
+		return Record{}, err
+	}
+
+	if err := db.save(); err != nil {
+		return Record{}, err
+	}
+	db.logAudit(ctx, AuditOperationDelete, &record, nil)
+
+	return record, nil
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPutRecordByName(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	ctx := context.Background()
+
+	created, isNew, err := db.PutRecordByName(ctx, "NAS", "192.168.1.10", 0)
+	if err != nil || !isNew {
+		t.Fatalf("PutRecordByName() = %v, %v, want a new record", isNew, err)
+	}
+
+	// the same record again is left unchanged
+	record, isNew, err := db.PutRecordByName(ctx, "NAS", "192.168.1.10", 0)
+	if err != nil || isNew || !reflect.DeepEqual(record, created) {
+		t.Errorf("PutRecordByName() = %+v, %v, %v, want %+v", record, isNew, err, created)
+	}
+
+	record, isNew, err = db.PutRecordByName(ctx, "nas", "192.168.1.11", created.Version)
+	if err != nil || isNew {
+		t.Fatalf("PutRecordByName() = %v, %v, want the existing record", isNew, err)
+	}
+	if record.ID != created.ID || record.Name != "nas" || record.Target != "192.168.1.11" {
+		t.Errorf("PutRecordByName() = %+v", record)
+	}
+
+	if _, _, err := db.PutRecordByName(ctx, "nas", "192.168.1.12", created.Version); err != ErrVersionMismatch {
+		t.Errorf("PutRecordByName() error = %v, want %v", err, ErrVersionMismatch)
+	}
+
+	if _, err := db.GetRecordByName("Nas"); err != nil {
+		t.Errorf("GetRecordByName() error = %v", err)
+	}
+	if _, err := db.DeleteRecordByName(ctx, "NAS", 0); err != nil {
+		t.Errorf("DeleteRecordByName() error = %v", err)
+	}
+	if _, err := db.GetRecordByName("nas"); err != ErrNotFound {
+		t.Errorf("GetRecordByName() error = %v, want %v", err, ErrNotFound)
+	}
+}
+
+func TestUniqueNames(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	ctx := context.Background()
+
+	if _, err := db.AddRecord(ctx, "NAS", "192.168.1.10"); err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	printer, err := db.AddRecord(ctx, "printer", "192.168.1.9")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	// the names only differing by their case are the same name
+	if _, err := db.AddRecord(ctx, "nas", "192.168.1.11"); err != ErrAlreadyExists {
+		t.Errorf("AddRecord() error = %v, want %v", err, ErrAlreadyExists)
+	}
+	if _, err := db.UpdateRecord(ctx, printer.ID, "Nas", printer.Target, 0); err != ErrAlreadyExists {
+		t.Errorf("UpdateRecord() error = %v, want %v", err, ErrAlreadyExists)
+	}
+
+	// a record can change the case of its own name
+	if _, err := db.UpdateRecord(ctx, printer.ID, "Printer", printer.Target, 0); err != nil {
+		t.Errorf("UpdateRecord() error = %v", err)
+	}
+
+	if _, err := db.GetRecordByName("nas"); err != nil {
+		t.Errorf("GetRecordByName() error = %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/juju/errors"
+)
+
+type ReconcileResult struct {
+	Created   []Record
+	Updated   []Record
+	Deleted   []Record
+	Conflicts []string
+}
+
+func (r ReconcileResult) Changed() bool {
+	return len(r.Created) > 0 || len(r.Updated) > 0 || len(r.Deleted) > 0
+}
+
+// ReconcileRecords makes the records managed by the source match the desired ones.
+// The names used by the other records are reported as conflicts.
+func (db *Database) ReconcileRecords(ctx context.Context, managedBy string, desired []Record) (ReconcileResult, error) {
+	var result ReconcileResult
+
+	names := map[string]bool{}
+	for _, record := range desired {
+		if err := validateName(record.Name); err != nil {
+			return result, errors.NewBadRequest(err, fmt.Sprintf("invalid name %q", record.Name))
+		}
+		if err := validateTarget(record.Target); err != nil {
+			return result, errors.NewBadRequest(err, fmt.Sprintf("invalid target %q of %q", record.Target, record.Name))
+		}
+		if names[strings.ToLower(record.Name)] {
+			return result, errors.NewBadRequest(nil, fmt.Sprintf("duplicated name %q", record.Name))
+		}
+		names[strings.ToLower(record.Name)] = true
+	}
+
+	db.mut.Lock()
+	defer db.mut.Unlock()
+
+	records := make([]Record, 0, len(db.data.Records)+len(desired))
+	before := map[string]Record{}
+	existing := map[string]int{}
+	for _, record := range db.data.Records {
+		if record.ManagedBy == managedBy && !names[strings.ToLower(record.Name)] {
+			result.Deleted = append(result.Deleted, record)
+			continue
+		}
+		existing[strings.ToLower(record.Name)] = len(records)
+		records = append(records, record)
+	}
+
+	for _, record := range desired {
+		i, ok := existing[strings.ToLower(record.Name)]
+		if !ok {
+			record.ID = uuid.NewString()
+			record.Enabled = true
+			record.ManagedBy = managedBy
+			records = append(records, record)
+			result.Created = append(result.Created, record)
+			continue
+		}
+
+		if records[i].ManagedBy != managedBy {
+			result.Conflicts = append(result.Conflicts, record.Name)
+			continue
+		}
+
+		if records[i].Name != record.Name || records[i].Target != record.Target {
+			before[records[i].ID] = records[i]
+			records[i].Name = record.Name
+			records[i].Target = record.Target
+			result.Updated = append(result.Updated, records[i])
+		}
+	}
+
+	if !result.Changed() {
+		return result, nil
+	}
+
+	previous := db.data.Records
+	previousHistory := maps.Clone(db.data.History)
+	previousTombstones := slices.Clone(db.data.Tombstones)
+	db.data.Records = records
+
+	index := map[string]int{}
+	for i, record := range records {
+		index[record.ID] = i
+	}
+
+	for i, created := range result.Created {
+		record := &records[index[created.ID]]
+		db.recordChange(ctx, AuditOperationCreate, nil, record)
+		result.Created[i] = *record
+	}
+	for i, updated := range result.Updated {
+		old := before[updated.ID]
+		record := &records[index[updated.ID]]
+		db.recordChange(ctx, AuditOperationUpdate, &old, record)
+		result.Updated[i] = *record
+	}
+	for i := range result.Deleted {
+		db.recordChange(ctx, AuditOperationDelete, &result.Deleted[i], nil)
+	}
+
+	if err := db.save(); err != nil {
+		db.data.Records = previous
+		db.data.History = previousHistory
+		db.data.Tombstones = previousTombstones
+		return ReconcileResult{}, err
+	}
+
+	for i := range result.Created {
+		db.logAudit(ctx, AuditOperationCreate, nil, &result.Created[i])
+	}
+	for i := range result.Updated {
+		record := before[result.Updated[i].ID]
+		db.logAudit(ctx, AuditOperationUpdate, &record, &result.Updated[i])
+	}
+	for i := range result.Deleted {
+		db.logAudit(ctx, AuditOperationDelete, &result.Deleted[i], nil)
+	}
+
+	return result, nil
+}
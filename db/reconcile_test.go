@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestReconcileRecords(t *testing.T) {
+	db := &Database{cfg: &config{Path: filepath.Join(t.TempDir(), "db.json")}}
+	db.data.Records = []Record{
+		{Base: Base{ID: "1"}, Name: "manual", Target: "192.168.1.1"},
+		{Base: Base{ID: "2"}, Name: "nas", Target: "192.168.1.2", ManagedBy: "file"},
+		{Base: Base{ID: "3"}, Name: "old", Target: "192.168.1.3", ManagedBy: "file"},
+		{Base: Base{ID: "4"}, Name: "printer", Target: "192.168.1.4", ManagedBy: "file"},
+	}
+
+	result, err := db.ReconcileRecords(context.Background(), "file", []Record{
+		{Name: "manual", Target: "192.168.1.10"},
+		{Name: "nas", Target: "192.168.1.20"},
+		{Name: "printer", Target: "192.168.1.4"},
+		{Name: "new", Target: "192.168.1.5"},
+	})
+	if err != nil {
+		t.Fatalf("ReconcileRecords() error = %v", err)
+	}
+
+	if len(result.Created) != 1 || result.Created[0].Name != "new" || result.Created[0].ManagedBy != "file" {
+		t.Errorf("ReconcileRecords() created = %v", result.Created)
+	}
+	if len(result.Updated) != 1 || result.Updated[0].Name != "nas" || result.Updated[0].Target != "192.168.1.20" {
+		t.Errorf("ReconcileRecords() updated = %v", result.Updated)
+	}
+	if len(result.Deleted) != 1 || result.Deleted[0].Name != "old" {
+		t.Errorf("ReconcileRecords() deleted = %v", result.Deleted)
+	}
+	if len(result.Conflicts) != 1 || result.Conflicts[0] != "manual" {
+		t.Errorf("ReconcileRecords() conflicts = %v", result.Conflicts)
+	}
+	if got := len(db.GetRecords()); got != 4 {
+		t.Errorf("GetRecords() returned %d records, want 4", got)
+	}
+
+	if _, err := db.ReconcileRecords(context.Background(), "file", []Record{{Name: "nas", Target: "invalid"}}); err == nil {
+		t.Error("ReconcileRecords() error = nil, want an invalid target error")
+	}
+	if err := db.DeleteRecord(context.Background(), result.Created[0].ID, 0); err != ErrManaged {
+		t.Errorf("DeleteRecord() error = %v, want %v", err, ErrManaged)
+	}
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/juju/errors"
+)
+
+const (
+	selectorEquals    = "="
+	selectorNotEquals = "!="
+	selectorExists    = "exists"
+	selectorNotExists = "!exists"
+)
+
+type requirement struct {
+	key      string
+	operator string
+	value    string
+}
+
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+
+	switch r.operator {
+	case selectorEquals:
+		return ok && value == r.value
+	case selectorNotEquals:
+		return !ok || value != r.value
+	case selectorExists:
+		return ok
+	case selectorNotExists:
+		return !ok
+	}
+
+	return false
+}
+
+type Selector []requirement
+
+// ParseSelector parses comma-separated key=value, key!=value, key and !key requirements.
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var r requirement
+		if key, value, ok := strings.Cut(part, "!="); ok {
+			r = requirement{key: key, operator: selectorNotEquals, value: value}
+		} else if key, value, ok := strings.Cut(part, "=="); ok {
+			r = requirement{key: key, operator: selectorEquals, value: value}
+		} else if key, value, ok := strings.Cut(part, "="); ok {
+			r = requirement{key: key, operator: selectorEquals, value: value}
+		} else if key, ok := strings.CutPrefix(part, "!"); ok {
+			r = requirement{key: key, operator: selectorNotExists}
+		} else {
+			r = requirement{key: part, operator: selectorExists}
+		}
+
+		r.key = strings.TrimSpace(r.key)
+		r.value = strings.TrimSpace(r.value)
+
+		if !validateLabelKeyRegexp.MatchString(r.key) {
+			return nil, errors.NewBadRequest(nil, fmt.Sprintf("invalid label key %q in the selector", r.key))
+		}
+		if !validateLabelValueRegexp.MatchString(r.value) {
+			return nil, errors.NewBadRequest(nil, fmt.Sprintf("invalid label value %q in the selector", r.value))
+		}
+
+		s = append(s, r)
+	}
+
+	return s, nil
+}
+
+func (s Selector) Empty() bool {
+	return len(s) == 0
+}
+
+// Positive returns true when the selector never matches the records without labels.
+func (s Selector) Positive() bool {
+	for _, r := range s {
+		if r.operator == selectorEquals || r.operator == selectorExists {
+			return true
+		}
+	}
+	return false
+}
+
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package db
+
+import "testing"
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"env": "ci", "team": "net"}
+
+	tests := []struct {
+		selector string
+		want     bool
+		wantErr  bool
+	}{
+		{selector: "", want: true},
+		{selector: "env=ci", want: true},
+		{selector: "env==ci, team=net", want: true},
+		{selector: "env=ci,team=dev", want: false},
+		{selector: "env!=prod", want: true},
+		{selector: "owner!=me", want: true},
+		{selector: "team", want: true},
+		{selector: "owner", want: false},
+		{selector: "!owner", want: true},
+		{selector: "!env", want: false},
+		{selector: "bad key=x", wantErr: true},
+		{selector: "env=a,b", wantErr: false, want: false},
+		{selector: "=ci", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.selector)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := s.Matches(labels); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelector_Positive(t *testing.T) {
+	tests := []struct {
+		selector string
+		want     bool
+	}{
+		{selector: "", want: false},
+		{selector: "env=ci", want: true},
+		{selector: "team", want: true},
+		{selector: "!temporary", want: false},
+		{selector: "env!=prod", want: false},
+		{selector: "!temporary,env=ci", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseSelector() error = %v", err)
+			}
+			if got := s.Positive(); got != tt.want {
+				t.Errorf("Positive() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,8 +1,10 @@
 package db
 
 import (
+	"fmt"
 	"net/netip"
 	"regexp"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/juju/errors"
@@ -34,3 +36,37 @@ func validateTarget(target string) error {
 	}
 	return nil
 }
+
+func ValidateName(name string) error {
+	return validateName(name)
+}
+
+func ValidateTarget(target string) error {
+	return validateTarget(target)
+}
+
+var (
+	validateLabelKeyRegexp   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9_\-\./]{0,61}[a-zA-Z0-9])?$`)
+	validateLabelValueRegexp = regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9_\-\.]{0,61}[a-zA-Z0-9])?)?$`)
+)
+
+const maxDescriptionLength = 1024
+
+func validateLabels(labels map[string]string) error {
+	for key, value := range labels {
+		if !validateLabelKeyRegexp.MatchString(key) {
+			return errors.NewBadRequest(nil, fmt.Sprintf("invalid label key %q", key))
+		}
+		if !validateLabelValueRegexp.MatchString(value) {
+			return errors.NewBadRequest(nil, fmt.Sprintf("invalid value of the label %q", key))
+		}
+	}
+	return nil
+}
+
+func validateDescription(description string) error {
+	if utf8.RuneCountInString(description) > maxDescriptionLength {
+		return errors.NewBadRequest(nil, fmt.Sprintf("the description is longer than %d characters", maxDescriptionLength))
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionUnchanged = "unchanged"
+	ActionConflict  = "conflict"
+	ActionInvalid   = "invalid"
+)
+
+const (
+	OnConflictSkip      = "skip"
+	OnConflictOverwrite = "overwrite"
+	OnConflictFail      = "fail"
+)
+
+type Options struct {
+	OnConflict string
+	Replace    bool
+	DryRun     bool
+}
+
+type PlanEntry struct {
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+	Previous string `json:"previous,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type Result struct {
+	DryRun  bool        `json:"dry_run"`
+	Entries []PlanEntry `json:"entries"`
+}
+
+func (r *Result) Count(action string) int {
+	count := 0
+	for _, entry := range r.Entries {
+		if entry.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// Import plans the changes of the records, and applies them unless it is a dry-run.
+func Import(ctx context.Context, database *db.Database, entries []Entry, opts Options) (*Result, error) {
+	switch opts.OnConflict {
+	case "":
+		opts.OnConflict = OnConflictSkip
+	case OnConflictSkip, OnConflictOverwrite, OnConflictFail:
+	default:
+		return nil, errors.NewBadRequest(nil, fmt.Sprintf("invalid conflict policy %q", opts.OnConflict))
+	}
+
+	records := map[string]db.Record{}
+	for _, record := range database.GetRecords() {
+		records[strings.ToLower(record.Name)] = record
+	}
+
+	result := &Result{
+		DryRun:  opts.DryRun,
+		Entries: []PlanEntry{},
+	}
+
+	imported := map[string]bool{}
+	for _, entry := range entries {
+		plan := PlanEntry{
+			Name:   entry.Name,
+			Target: entry.Target,
+			Line:   entry.Line,
+		}
+		key := strings.ToLower(entry.Name)
+
+		if err := db.ValidateName(entry.Name); err != nil {
+			plan.Action = ActionInvalid
+			plan.Reason = err.Error()
+		} else if err := db.ValidateTarget(entry.Target); err != nil {
+			plan.Action = ActionInvalid
+			plan.Reason = err.Error()
+		} else if ip := net.ParseIP(entry.Target); ip.To4() == nil {
+			plan.Action = ActionInvalid
+			plan.Reason = "only the IPv4 targets are supported"
+		} else if ip.IsLoopback() || ip.IsUnspecified() {
+			plan.Action = ActionInvalid
+			plan.Reason = "loopback and unspecified targets are ignored"
+		} else if imported[key] {
+			plan.Action = ActionInvalid
+			plan.Reason = "duplicated name"
+		} else if record, ok := records[key]; !ok {
+			plan.Action = ActionCreate
+		} else {
+			plan.Previous = record.Target
+
+			switch {
+			case record.Target == entry.Target:
+				plan.Action = ActionUnchanged
+
+			case record.ManagedBy != "":
+				plan.Action = ActionConflict
+				plan.Reason = fmt.Sprintf("the record is managed by %s", record.ManagedBy)
+
+			case record.Protected:
+				plan.Action = ActionConflict
+				plan.Reason = "the record is protected"
+
+			case opts.OnConflict == OnConflictOverwrite:
+				plan.Action = ActionUpdate
+
+			default:
+				plan.Action = ActionConflict
+				plan.Reason = "a record already exists with another target"
+			}
+		}
+
+		if plan.Action != ActionInvalid {
+			imported[key] = true
+		}
+
+		result.Entries = append(result.Entries, plan)
+	}
+
+	if opts.Replace {
+		for _, record := range database.GetRecords() {
+			if imported[strings.ToLower(record.Name)] || record.ManagedBy != "" || record.Protected {
+				continue
+			}
+
+			result.Entries = append(result.Entries, PlanEntry{
+				Name:   record.Name,
+				Target: record.Target,
+				Action: ActionDelete,
+			})
+		}
+	}
+
+	if opts.OnConflict == OnConflictFail && result.Count(ActionConflict) > 0 {
+		return result, errors.NewAlreadyExists(nil, fmt.Sprintf("%d conflicting records", result.Count(ActionConflict)))
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	// the plan is applied as a whole
+	operations := []db.BatchOperation{}
+	planned := []PlanEntry{}
+	for _, plan := range result.Entries {
+		record := records[strings.ToLower(plan.Name)]
+
+		switch plan.Action {
+		case ActionCreate:
+			operations = append(operations, db.BatchOperation{Operation: db.BatchOperationCreate, Name: plan.Name, Target: plan.Target})
+
+		case ActionUpdate:
+			operations = append(operations, db.BatchOperation{Operation: db.BatchOperationUpdate, ID: record.ID, Name: record.Name, Target: plan.Target, Version: record.Version})
+
+		case ActionDelete:
+			operations = append(operations, db.BatchOperation{Operation: db.BatchOperationDelete, ID: record.ID, Version: record.Version})
+
+		default:
+			continue
+		}
+		planned = append(planned, plan)
+	}
+
+	if len(operations) == 0 {
+		return result, nil
+	}
+
+	results, err := database.ApplyBatch(ctx, operations)
+	if err != nil {
+		for i, r := range results {
+			if r.Err != nil {
+				return result, fmt.Errorf("unable to %s the record %q, nothing imported: %w", planned[i].Action, planned[i].Name, r.Err)
+			}
+		}
+		return result, fmt.Errorf("unable to import the records: %w", err)
+	}
+
+	return result, nil
+}
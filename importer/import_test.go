@@ -0,0 +1,70 @@
+package importer
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ovh/configstore"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+func newTestDatabase(t *testing.T, path string) *db.Database {
+	configstore.InMemory(t.Name()).Add(configstore.NewItem("DB_PATH", path, 1))
+	t.Cleanup(func() { configstore.UnregisterProvider(t.Name()) })
+
+	database, err := db.NewDatabase(context.Background())
+	if err != nil {
+		t.Fatalf("NewDatabase() error = %v", err)
+	}
+	return database
+}
+
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	database := newTestDatabase(t, filepath.Join(t.TempDir(), "db.json"))
+
+	if _, err := database.AddRecord(ctx, "nas", "192.168.1.10"); err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	if _, err := database.AddRecord(ctx, "old", "192.168.1.11"); err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	entries := []Entry{
+		{Name: "nas", Target: "192.168.1.20", Line: 1},
+		{Name: "printer", Target: "192.168.1.21", Line: 2},
+	}
+	result, err := Import(ctx, database, entries, Options{OnConflict: OnConflictOverwrite, Replace: true})
+	if err != nil {
+		t.Fatalf("Import() error = %v", err)
+	}
+	if result.Count(ActionCreate) != 1 || result.Count(ActionUpdate) != 1 || result.Count(ActionDelete) != 1 {
+		t.Errorf("Import() entries = %+v", result.Entries)
+	}
+
+	got := map[string]string{}
+	for _, record := range database.GetRecords() {
+		got[record.Name] = record.Target
+	}
+	if len(got) != 2 || got["nas"] != "192.168.1.20" || got["printer"] != "192.168.1.21" {
+		t.Errorf("GetRecords() = %v", got)
+	}
+}
+
+func TestImport_allOrNothing(t *testing.T) {
+	// the database cannot be saved in a missing directory
+	database := newTestDatabase(t, filepath.Join(t.TempDir(), "missing", "db.json"))
+
+	entries := []Entry{
+		{Name: "nas", Target: "192.168.1.20", Line: 1},
+		{Name: "printer", Target: "192.168.1.21", Line: 2},
+	}
+	if _, err := Import(context.Background(), database, entries, Options{}); err == nil {
+		t.Fatal("Import() error = nil")
+	}
+	if records := database.GetRecords(); len(records) != 0 {
+		t.Errorf("GetRecords() = %v, want none", records)
+	}
+}
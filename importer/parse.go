@@ -0,0 +1,224 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	FormatHosts  = "hosts"
+	FormatCSV    = "csv"
+	FormatBIND   = "bind"
+	FormatPihole = "pihole"
+)
+
+var Formats = []string{FormatHosts, FormatCSV, FormatBIND, FormatPihole}
+
+type Entry struct {
+	Name   string
+	Target string
+	Line   int
+}
+
+// Parse qualifies the relative names of the BIND zones with the origin.
+func Parse(r io.Reader, format, origin string) ([]Entry, error) {
+	switch format {
+	case FormatHosts:
+		return ParseHosts(r)
+
+	case FormatCSV:
+		return ParseCSV(r)
+
+	case FormatBIND:
+		return ParseBIND(r, origin)
+
+	case FormatPihole:
+		return ParsePihole(r)
+	}
+
+	return nil, fmt.Errorf("unsupported format %q (supported formats: %s)", format, strings.Join(Formats, ", "))
+}
+
+func ParseHosts(r io.Reader) ([]Entry, error) {
+	entries := []Entry{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+
+		fields := strings.Fields(text)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: missing hostname", line)
+		}
+
+		for _, name := range fields[1:] {
+			entries = append(entries, Entry{
+				Name:   name,
+				Target: fields[0],
+				Line:   line,
+			})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func ParsePihole(r io.Reader) ([]Entry, error) {
+	return ParseHosts(r)
+}
+
+// ParseCSV locates the columns with an optional header row (name, target or ip).
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	entries := []Entry{}
+	nameCol, targetCol := 0, 1
+
+	for row := 0; ; row++ {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if row == 0 {
+			header := map[string]int{}
+			for i, field := range fields {
+				header[strings.ToLower(strings.TrimSpace(field))] = i
+			}
+			if i, ok := header["name"]; ok {
+				nameCol = i
+				if i, ok := header["target"]; ok {
+					targetCol = i
+				} else if i, ok := header["ip"]; ok {
+					targetCol = i
+				} else {
+					return nil, fmt.Errorf("line %d: missing target column", line)
+				}
+				continue
+			}
+		}
+
+		if len(fields) <= nameCol || len(fields) <= targetCol {
+			return nil, fmt.Errorf("line %d: missing columns", line)
+		}
+
+		entries = append(entries, Entry{
+			Name:   strings.TrimSpace(fields[nameCol]),
+			Target: strings.TrimSpace(fields[targetCol]),
+			Line:   line,
+		})
+	}
+
+	return entries, nil
+}
+
+// ParseBIND reads the A records, qualified with $ORIGIN or the given origin.
+func ParseBIND(r io.Reader, origin string) ([]Entry, error) {
+	entries := []Entry{}
+	origin = strings.TrimSuffix(origin, ".")
+	owner := ""
+	depth := 0
+
+	qualify := func(name string) string {
+		switch {
+		case name == "@":
+			return origin
+		case strings.HasSuffix(name, "."):
+			return strings.TrimSuffix(name, ".")
+		case origin == "":
+			return name
+		}
+		return name + "." + origin
+	}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		raw, _, _ := strings.Cut(scanner.Text(), ";")
+
+		// skip the content of the multi-line records (e.g. SOA)
+		inParens := depth > 0
+		depth += strings.Count(raw, "(") - strings.Count(raw, ")")
+		if inParens {
+			continue
+		}
+
+		fields := strings.Fields(raw)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(fields[0]) {
+		case "$ORIGIN":
+			if len(fields) < 2 {
+				return nil, fmt.Errorf("line %d: missing origin", line)
+			}
+			origin = qualify(fields[1])
+			continue
+
+		case "$TTL", "$INCLUDE", "$GENERATE":
+			continue
+		}
+
+		// a line starting with a blank reuses the previous owner
+		if raw[0] != ' ' && raw[0] != '\t' {
+			owner = qualify(fields[0])
+			fields = fields[1:]
+		}
+
+		// skip the TTL and the class
+		for len(fields) > 0 && (isTTL(fields[0]) || isClass(fields[0])) {
+			fields = fields[1:]
+		}
+
+		if len(fields) < 2 || strings.ToUpper(fields[0]) != "A" {
+			continue
+		}
+		if owner == "" {
+			return nil, fmt.Errorf("line %d: missing owner name", line)
+		}
+
+		entries = append(entries, Entry{
+			Name:   owner,
+			Target: fields[1],
+			Line:   line,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func isTTL(field string) bool {
+	if field == "" || field[0] < '0' || field[0] > '9' {
+		return false
+	}
+	return strings.Trim(strings.ToLower(field), "0123456789smhdw") == ""
+}
+
+func isClass(field string) bool {
+	switch strings.ToUpper(field) {
+	case "IN", "CH", "HS":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func entriesString(entries []Entry) string {
+	parts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		parts = append(parts, fmt.Sprintf("%d:%s=%s", entry.Line, entry.Name, entry.Target))
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		format  string
+		origin  string
+		data    string
+		want    string
+		wantErr bool
+	}{
+		{
+			format: FormatHosts,
+			data:   "# comment\n127.0.0.1\tlocalhost\n\n192.168.1.10 nas nas.home.arpa # storage\n",
+			want:   "2:localhost=127.0.0.1,4:nas=192.168.1.10,4:nas.home.arpa=192.168.1.10",
+		},
+		{
+			format:  FormatHosts,
+			data:    "192.168.1.10\n",
+			wantErr: true,
+		},
+		{
+			format: FormatPihole,
+			data:   "192.168.1.10 nas.home.arpa\n192.168.1.11 printer.home.arpa\n",
+			want:   "1:nas.home.arpa=192.168.1.10,2:printer.home.arpa=192.168.1.11",
+		},
+		{
+			format: FormatCSV,
+			data:   "nas,192.168.1.10\nprinter, 192.168.1.11\n",
+			want:   "1:nas=192.168.1.10,2:printer=192.168.1.11",
+		},
+		{
+			format: FormatCSV,
+			data:   "ip,Name,comment\n192.168.1.10,nas,storage\n",
+			want:   "2:nas=192.168.1.10",
+		},
+		{
+			format:  FormatCSV,
+			data:    "name,comment\nnas,storage\n",
+			wantErr: true,
+		},
+		{
+			format: FormatBIND,
+			origin: "example.com",
+			data: `$TTL 3600
+@	IN	SOA	ns1 admin (
+		2024010101 ; serial
+		3600 )
+	IN	NS	ns1
+	IN	A	192.168.1.1
+ns1	IN	A	192.168.1.2
+nas 300 IN A 192.168.1.10 ; storage
+	A	192.168.1.11
+www	IN	CNAME	nas
+$ORIGIN lab.example.com.
+srv1	A	10.0.0.1
+gw.example.org.	IN	A	10.0.0.254
+`,
+			want: "6:example.com=192.168.1.1,7:ns1.example.com=192.168.1.2,8:nas.example.com=192.168.1.10,9:nas.example.com=192.168.1.11,12:srv1.lab.example.com=10.0.0.1,13:gw.example.org=10.0.0.254",
+		},
+		{
+			format:  "unknown",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			entries, err := Parse(strings.NewReader(tt.data), tt.format, tt.origin)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := entriesString(entries); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"fmt"
+
+	"github.com/ovh/configstore"
+)
+
+const (
+	keySource = "INVENTORY_SOURCE"
+
+	sourceTypeDnsmasq = "dnsmasq"
+	sourceTypeISC     = "isc"
+	sourceTypeKea     = "kea"
+	sourceTypeDocker  = "docker"
+)
+
+type sourceConfig struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Path     string `json:"path"`
+	Priority int    `json:"priority"`
+}
+
+func loadConfig() ([]*sourceConfig, error) {
+	items, err := configstore.Filter().Slice(keySource).Unmarshal(func() interface{} { return &sourceConfig{} }).GetItemList()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the inventory sources: %w", err)
+	}
+
+	sources := make([]*sourceConfig, 0, len(items.Items))
+	for _, item := range items.Items {
+		raw, err := item.Unmarshaled()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the inventory source: %w", err)
+		}
+
+		cfg := raw.(*sourceConfig)
+		if cfg.Type == "" {
+			return nil, fmt.Errorf("invalid inventory source: the type is required")
+		}
+		if cfg.Name == "" {
+			cfg.Name = cfg.Type
+		}
+
+		sources = append(sources, cfg)
+	}
+
+	return sources, nil
+}
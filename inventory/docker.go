@@ -0,0 +1,169 @@
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+)
+
+const (
+	DockerNameLabel = "usg-dns-api.name"
+
+	// DockerNetworkLabel restricts the published addresses to a single network.
+	DockerNetworkLabel = "usg-dns-api.network"
+
+	defaultDockerSocket = "/var/run/docker.sock"
+	dockerTimeout       = 10 * time.Second
+)
+
+type Docker struct {
+	name     string
+	socket   string
+	priority int
+	client   *http.Client
+}
+
+type dockerContainer struct {
+	ID              string            `json:"Id"`
+	Labels          map[string]string `json:"Labels"`
+	NetworkSettings struct {
+		Networks map[string]struct {
+			IPAddress  string `json:"IPAddress"`
+			MacAddress string `json:"MacAddress"`
+		} `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+func NewDocker(name, socket string, priority int) *Docker {
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", socket)
+		},
+	}
+
+	return &Docker{
+		name:     name,
+		socket:   socket,
+		priority: priority,
+		client:   &http.Client{Transport: transport},
+	}
+}
+
+func (d *Docker) Name() string {
+	return d.name
+}
+
+func (d *Docker) Priority() int {
+	return d.priority
+}
+
+func (d *Docker) get(ctx context.Context, path string, filters map[string][]string) (*http.Response, error) {
+	query := url.Values{}
+	if len(filters) > 0 {
+		data, err := json.Marshal(filters)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode the filters: %w", err)
+		}
+		query.Set("filters", string(data))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://docker"+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the request: %w", err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach the docker engine on %s: %w", d.socket, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected response from the docker engine: %s", resp.Status)
+	}
+
+	return resp, nil
+}
+
+func (d *Docker) Hosts(ctx context.Context) ([]Host, error) {
+	ctx, cancel := context.WithTimeout(ctx, dockerTimeout)
+	defer cancel()
+
+	resp, err := d.get(ctx, "/containers/json", map[string][]string{"label": {DockerNameLabel}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var containers []dockerContainer
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return nil, fmt.Errorf("unable to decode the containers: %w", err)
+	}
+
+	hosts := []Host{}
+	for _, container := range containers {
+		name := container.Labels[DockerNameLabel]
+		if name == "" {
+			continue
+		}
+
+		// iterate over the networks in a stable order
+		networks := make([]string, 0, len(container.NetworkSettings.Networks))
+		for network := range container.NetworkSettings.Networks {
+			networks = append(networks, network)
+		}
+		sort.Strings(networks)
+
+		for _, network := range networks {
+			if only, ok := container.Labels[DockerNetworkLabel]; ok && only != network {
+				continue
+			}
+
+			settings := container.NetworkSettings.Networks[network]
+
+			ip := net.ParseIP(settings.IPAddress)
+			if ip == nil {
+				continue
+			}
+			mac, _ := net.ParseMAC(settings.MacAddress)
+
+			hosts = append(hosts, Host{
+				Name: name,
+				IP:   ip,
+				MAC:  mac,
+			})
+		}
+	}
+
+	return hosts, nil
+}
+
+func (d *Docker) Watch(ctx context.Context, notify func()) error {
+	resp, err := d.get(ctx, "/events", map[string][]string{
+		"type":  {"container", "network"},
+		"event": {"start", "die", "destroy", "rename", "connect", "disconnect"},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var event json.RawMessage
+		if err := decoder.Decode(&event); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("unable to read the docker events: %w", err)
+		}
+
+		notify()
+	}
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newFakeDocker(t *testing.T, handler http.Handler) string {
+	socket := filepath.Join(t.TempDir(), "docker.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("unable to listen on %s: %v", socket, err)
+	}
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = listener
+	server.Start()
+	t.Cleanup(server.Close)
+
+	return socket
+}
+
+func TestDockerHosts(t *testing.T) {
+	socket := newFakeDocker(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/containers/json" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.URL.Query().Get("filters"); got != `{"label":["usg-dns-api.name"]}` {
+			t.Errorf("unexpected filters %s", got)
+		}
+
+		fmt.Fprint(w, `[
+			{"Id": "1", "Labels": {"usg-dns-api.name": "grafana"}, "NetworkSettings": {"Networks": {
+				"macvlan": {"IPAddress": "192.168.1.20", "MacAddress": "02:42:c0:a8:01:14"},
+				"bridge": {"IPAddress": "172.17.0.2", "MacAddress": "02:42:ac:11:00:02"}
+			}}},
+			{"Id": "2", "Labels": {"usg-dns-api.name": "prometheus", "usg-dns-api.network": "macvlan"}, "NetworkSettings": {"Networks": {
+				"macvlan": {"IPAddress": "192.168.1.21", "MacAddress": "02:42:c0:a8:01:15"},
+				"bridge": {"IPAddress": "172.17.0.3", "MacAddress": "02:42:ac:11:00:03"}
+			}}},
+			{"Id": "3", "Labels": {"usg-dns-api.name": "host"}, "NetworkSettings": {"Networks": {
+				"host": {"IPAddress": "", "MacAddress": ""}
+			}}}
+		]`)
+	}))
+
+	hosts, err := NewDocker("docker", socket, 10).Hosts(context.Background())
+	if err != nil {
+		t.Fatalf("Hosts() error = %v", err)
+	}
+
+	want := "172.17.0.2=grafana/02:42:ac:11:00:02,192.168.1.20=grafana/02:42:c0:a8:01:14,192.168.1.21=prometheus/02:42:c0:a8:01:15"
+	if got := hostsString(hosts); got != want {
+		t.Errorf("Hosts() = %v, want %v", got, want)
+	}
+}
+
+func TestDockerWatch(t *testing.T) {
+	socket := newFakeDocker(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events" {
+			http.NotFound(w, r)
+			return
+		}
+
+		fmt.Fprintln(w, `{"Type": "container", "Action": "start", "Actor": {"ID": "1"}}`)
+		fmt.Fprintln(w, `{"Type": "network", "Action": "connect", "Actor": {"ID": "2"}}`)
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	notified := 0
+	err := NewDocker("docker", socket, 10).Watch(ctx, func() { notified++ })
+	if err == nil {
+		t.Fatal("Watch() error = nil, want the end of the event stream")
+	}
+	if notified != 2 {
+		t.Errorf("Watch() notified %d times, want 2", notified)
+	}
+}
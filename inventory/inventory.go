@@ -0,0 +1,26 @@
+package inventory
+
+import (
+	"context"
+	"net"
+)
+
+type Host struct {
+	Name string
+	IP   net.IP
+	MAC  net.HardwareAddr
+}
+
+type Source interface {
+	Name() string
+
+	// the host of the source with the highest priority wins
+	Priority() int
+
+	Hosts(ctx context.Context) ([]Host, error)
+}
+
+// Watcher is implemented by the sources able to notify the changes of their hosts.
+type Watcher interface {
+	Watch(ctx context.Context, notify func()) error
+}
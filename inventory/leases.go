@@ -0,0 +1,279 @@
+package inventory
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LeaseParser only returns the active leases.
+type LeaseParser func(r io.Reader, now time.Time) ([]Host, error)
+
+type LeaseFile struct {
+	name     string
+	path     string
+	priority int
+	parse    LeaseParser
+}
+
+func NewLeaseFile(name, path string, priority int, parse LeaseParser) *LeaseFile {
+	return &LeaseFile{
+		name:     name,
+		path:     path,
+		priority: priority,
+		parse:    parse,
+	}
+}
+
+func (l *LeaseFile) Name() string {
+	return l.name
+}
+
+func (l *LeaseFile) Priority() int {
+	return l.priority
+}
+
+func (l *LeaseFile) Hosts(ctx context.Context) ([]Host, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the lease file: %w", err)
+	}
+	defer f.Close()
+
+	hosts, err := l.parse(f, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the lease file %s: %w", l.path, err)
+	}
+
+	return hosts, nil
+}
+
+// ParseDnsmasqLeases parses a dnsmasq.leases file:
+//
+//	1700000000 00:11:22:33:44:55 192.168.1.10 nas 01:00:11:22:33:44:55
+func ParseDnsmasqLeases(r io.Reader, now time.Time) ([]Host, error) {
+	hosts := []Host{}
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+
+		// skip the DHCPv6 server DUID and the malformed lines
+		if len(fields) < 4 || fields[0] == "duid" {
+			continue
+		}
+
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid expiry: %w", line, err)
+		}
+		if expiry != 0 && time.Unix(expiry, 0).Before(now) {
+			continue
+		}
+
+		if fields[3] == "*" {
+			continue
+		}
+
+		ip := net.ParseIP(fields[2])
+		if ip == nil {
+			return nil, fmt.Errorf("line %d: invalid IP address %q", line, fields[2])
+		}
+
+		// DHCPv6 leases have an IAID instead of a MAC address
+		mac, _ := net.ParseMAC(fields[1])
+
+		hosts = append(hosts, Host{
+			Name: fields[3],
+			IP:   ip,
+			MAC:  mac,
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return hosts, nil
+}
+
+// ParseISCLeases keeps the last declaration of a lease, as dhcpd appends the updates.
+func ParseISCLeases(r io.Reader, now time.Time) ([]Host, error) {
+	type lease struct {
+		Host
+		Active bool
+		Ends   time.Time
+	}
+
+	leases := map[string]*lease{}
+	order := []string{}
+
+	var current *lease
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if current == nil {
+			address, ok := strings.CutPrefix(text, "lease ")
+			if !ok {
+				continue
+			}
+			address = strings.TrimSpace(strings.TrimSuffix(address, "{"))
+
+			ip := net.ParseIP(address)
+			if ip == nil {
+				return nil, fmt.Errorf("line %d: invalid IP address %q", line, address)
+			}
+
+			current = &lease{Host: Host{IP: ip}}
+			continue
+		}
+
+		if text == "}" {
+			key := current.IP.String()
+			if _, ok := leases[key]; !ok {
+				order = append(order, key)
+			}
+			leases[key] = current
+			current = nil
+			continue
+		}
+
+		statement := strings.TrimSuffix(text, ";")
+		switch {
+		case strings.HasPrefix(statement, "binding state "):
+			current.Active = strings.TrimPrefix(statement, "binding state ") == "active"
+
+		case strings.HasPrefix(statement, "hardware ethernet "):
+			current.MAC, _ = net.ParseMAC(strings.TrimPrefix(statement, "hardware ethernet "))
+
+		case strings.HasPrefix(statement, "client-hostname "):
+			current.Name = strings.Trim(strings.TrimPrefix(statement, "client-hostname "), `"`)
+
+		case strings.HasPrefix(statement, "ends "):
+			value := strings.TrimPrefix(statement, "ends ")
+			if value == "never" {
+				continue
+			}
+
+			// ends <weekday> <yyyy/mm/dd> <hh:mm:ss>
+			parts := strings.Fields(value)
+			if len(parts) != 3 {
+				return nil, fmt.Errorf("line %d: invalid end date %q", line, value)
+			}
+			ends, err := time.Parse("2006/01/02 15:04:05", parts[1]+" "+parts[2])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid end date: %w", line, err)
+			}
+			current.Ends = ends
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	hosts := []Host{}
+	for _, key := range order {
+		lease := leases[key]
+		if !lease.Active || lease.Name == "" || (!lease.Ends.IsZero() && lease.Ends.Before(now)) {
+			continue
+		}
+		hosts = append(hosts, lease.Host)
+	}
+
+	return hosts, nil
+}
+
+func ParseKeaLeases(r io.Reader, now time.Time) ([]Host, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Host{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the header: %w", err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, name := range []string{"address", "hwaddr", "expire", "hostname", "state"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	// the lease file is append-only, the last entry of an address wins
+	leases := map[string]Host{}
+	order := []string{}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		field := func(name string) string {
+			if i := columns[name]; i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+
+		ip := net.ParseIP(field("address"))
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address %q", field("address"))
+		}
+		key := ip.String()
+
+		if _, ok := leases[key]; !ok {
+			order = append(order, key)
+		}
+
+		expire, err := strconv.ParseInt(field("expire"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expire value for %s: %w", key, err)
+		}
+
+		// the state 0 is the default (active) state
+		if field("state") != "0" || time.Unix(expire, 0).Before(now) || field("hostname") == "" {
+			delete(leases, key)
+			continue
+		}
+
+		mac, _ := net.ParseMAC(field("hwaddr"))
+
+		leases[key] = Host{
+			Name: strings.TrimSuffix(field("hostname"), "."),
+			IP:   ip,
+			MAC:  mac,
+		}
+	}
+
+	hosts := []Host{}
+	for _, key := range order {
+		if host, ok := leases[key]; ok {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts, nil
+}
@@ -0,0 +1,95 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func hostsString(hosts []Host) string {
+	parts := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		parts = append(parts, host.IP.String()+"="+host.Name+"/"+host.MAC.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestParseDnsmasqLeases(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	data := `1700003600 00:11:22:33:44:55 192.168.1.10 nas 01:00:11:22:33:44:55
+0 00:11:22:33:44:66 192.168.1.11 printer *
+1600000000 00:11:22:33:44:77 192.168.1.12 expired *
+1700003600 00:11:22:33:44:88 192.168.1.13 * *
+duid 00:01:00:01:2c:00:00:00:00:11:22:33:44:55
+`
+
+	hosts, err := ParseDnsmasqLeases(strings.NewReader(data), now)
+	if err != nil {
+		t.Fatalf("ParseDnsmasqLeases() error = %v", err)
+	}
+
+	want := "192.168.1.10=nas/00:11:22:33:44:55,192.168.1.11=printer/00:11:22:33:44:66"
+	if got := hostsString(hosts); got != want {
+		t.Errorf("ParseDnsmasqLeases() = %v, want %v", got, want)
+	}
+}
+
+func TestParseISCLeases(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := `# The format of this file is documented in the dhcpd.leases(5) manual page.
+lease 192.168.1.10 {
+  starts 1 2023/12/31 12:00:00;
+  ends 2 2024/01/02 12:00:00;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:55;
+  client-hostname "nas";
+}
+lease 192.168.1.11 {
+  ends never;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:66;
+  client-hostname "printer";
+}
+lease 192.168.1.12 {
+  ends 2 2023/12/01 12:00:00;
+  binding state active;
+  hardware ethernet 00:11:22:33:44:77;
+  client-hostname "expired";
+}
+lease 192.168.1.11 {
+  ends never;
+  binding state free;
+  hardware ethernet 00:11:22:33:44:66;
+}
+`
+
+	hosts, err := ParseISCLeases(strings.NewReader(data), now)
+	if err != nil {
+		t.Fatalf("ParseISCLeases() error = %v", err)
+	}
+
+	want := "192.168.1.10=nas/00:11:22:33:44:55"
+	if got := hostsString(hosts); got != want {
+		t.Errorf("ParseISCLeases() = %v, want %v", got, want)
+	}
+}
+
+func TestParseKeaLeases(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	data := `address,hwaddr,client_id,valid_lifetime,expire,subnet_id,fqdn_fwd,fqdn_rev,hostname,state,user_context
+192.168.1.10,00:11:22:33:44:55,,3600,1700003600,1,0,0,nas.example.com.,0,
+192.168.1.11,00:11:22:33:44:66,,3600,1700003600,1,0,0,printer,0,
+192.168.1.11,00:11:22:33:44:66,,3600,1700003600,1,0,0,printer,2,
+192.168.1.12,00:11:22:33:44:77,,3600,1600000000,1,0,0,expired,0,
+`
+
+	hosts, err := ParseKeaLeases(strings.NewReader(data), now)
+	if err != nil {
+		t.Fatalf("ParseKeaLeases() error = %v", err)
+	}
+
+	want := "192.168.1.10=nas.example.com/00:11:22:33:44:55"
+	if got := hostsString(hosts); got != want {
+		t.Errorf("ParseKeaLeases() = %v, want %v", got, want)
+	}
+}
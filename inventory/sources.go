@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+)
+
+func NewSources(ctx context.Context) ([]Source, error) {
+	// load the configuration
+	cfgs, err := loadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the configuration: %w", err)
+	}
+	logrus.WithContext(ctx).Debug("loaded the inventory configuration")
+
+	sources := make([]Source, 0, len(cfgs))
+	for _, cfg := range cfgs {
+		var source Source
+
+		if cfg.Path == "" && cfg.Type != sourceTypeDocker {
+			return nil, fmt.Errorf("invalid inventory source %q: the path is required", cfg.Name)
+		}
+
+		switch cfg.Type {
+		case sourceTypeDnsmasq:
+			source = NewLeaseFile(cfg.Name, cfg.Path, cfg.Priority, ParseDnsmasqLeases)
+
+		case sourceTypeISC:
+			source = NewLeaseFile(cfg.Name, cfg.Path, cfg.Priority, ParseISCLeases)
+
+		case sourceTypeKea:
+			source = NewLeaseFile(cfg.Name, cfg.Path, cfg.Priority, ParseKeaLeases)
+
+		case sourceTypeDocker:
+			if cfg.Path == "" {
+				cfg.Path = defaultDockerSocket
+			}
+			source = NewDocker(cfg.Name, cfg.Path, cfg.Priority)
+
+		default:
+			return nil, fmt.Errorf("unsupported inventory source type %q", cfg.Type)
+		}
+
+		sources = append(sources, source)
+	}
+
+	return sources, nil
+}
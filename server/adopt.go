@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+const (
+	AdoptActionCreate   = "create"
+	AdoptActionExists   = "exists"
+	AdoptActionConflict = "conflict"
+	AdoptActionInvalid  = "invalid"
+)
+
+type AdoptOptions struct {
+	Network string
+	Match   string
+	MACs    []string
+	DryRun  bool
+}
+
+type AdoptEntry struct {
+	MAC      string `json:"mac"`
+	Name     string `json:"name"`
+	IP       string `json:"ip"`
+	Network  string `json:"network,omitempty"`
+	Action   string `json:"action"`
+	Reason   string `json:"reason,omitempty"`
+	RecordID string `json:"record_id,omitempty"`
+}
+
+type AdoptResult struct {
+	DryRun  bool         `json:"dry_run"`
+	Entries []AdoptEntry `json:"entries"`
+}
+
+func Adopt(ctx context.Context, database *db.Database, client *unifi.Client, opts AdoptOptions) (*AdoptResult, error) {
+	if err := client.Login(ctx); err != nil {
+		return nil, fmt.Errorf("unable to login to the unifi-controller API: %w", err)
+	}
+
+	networks, err := client.GetNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the networks list: %w", err)
+	}
+
+	clients, err := client.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the clients list: %w", err)
+	}
+
+	entries, err := adoptEntries(ctx, clients, networks, database.GetRecords(), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AdoptResult{
+		DryRun:  opts.DryRun,
+		Entries: entries,
+	}
+	if opts.DryRun {
+		return result, nil
+	}
+
+	for i, entry := range result.Entries {
+		if entry.Action != AdoptActionCreate {
+			continue
+		}
+
+		record, err := database.AddRecord(ctx, entry.Name, entry.IP)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create the record %q: %w", entry.Name, err)
+		}
+		result.Entries[i].RecordID = record.ID
+	}
+
+	return result, nil
+}
+
+func adoptEntries(ctx context.Context, clients []unifi.User, networks []unifi.NetworkConf, existing []db.Record, opts AdoptOptions) ([]AdoptEntry, error) {
+	var match *regexp.Regexp
+	if opts.Match != "" {
+		re, err := regexp.Compile(opts.Match)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, "invalid match regex")
+		}
+		match = re
+	}
+
+	macs := make([]string, 0, len(opts.MACs))
+	for _, mac := range opts.MACs {
+		hwAddr, err := net.ParseMAC(mac)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, fmt.Sprintf("invalid MAC address %q", mac))
+		}
+		macs = append(macs, hwAddr.String())
+	}
+
+	records := map[string]db.Record{}
+	for _, record := range existing {
+		records[strings.ToLower(record.Name)] = record
+	}
+
+	entries := []AdoptEntry{}
+	for _, clt := range clients {
+		if !clt.UseFixedIP {
+			continue
+		}
+
+		entry := AdoptEntry{
+			MAC:  clt.HwAddress.String(),
+			Name: clientName(ctx, clt),
+			IP:   clt.FixedIP.String(),
+		}
+		if network, ok := unifi.ClientNetwork(clt, networks); ok {
+			entry.Network = network.Name
+		}
+
+		if len(macs) > 0 && !slices.Contains(macs, entry.MAC) {
+			continue
+		}
+		if opts.Network != "" && entry.Network != opts.Network {
+			continue
+		}
+		if match != nil && !match.MatchString(entry.Name) {
+			continue
+		}
+
+		if err := db.ValidateName(entry.Name); err != nil {
+			entry.Action = AdoptActionInvalid
+			entry.Reason = err.Error()
+		} else if record, ok := records[strings.ToLower(entry.Name)]; ok {
+			entry.RecordID = record.ID
+			if record.Target == entry.IP {
+				entry.Action = AdoptActionExists
+			} else {
+				entry.Action = AdoptActionConflict
+				entry.Reason = fmt.Sprintf("a record already exists with the target %s", record.Target)
+			}
+		} else {
+			entry.Action = AdoptActionCreate
+
+			// the next clients with the same name are compared with this one
+			records[strings.ToLower(entry.Name)] = db.Record{Name: entry.Name, Target: entry.IP}
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
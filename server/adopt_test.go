@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+func Test_adoptEntries(t *testing.T) {
+	mac := func(s string) net.HardwareAddr {
+		hwAddr, err := net.ParseMAC(s)
+		if err != nil {
+			t.Fatalf("ParseMAC() error = %v", err)
+		}
+		return hwAddr
+	}
+
+	networks := []unifi.NetworkConf{
+		{ID: "lan", Name: "LAN", Enabled: true},
+		{ID: "iot", Name: "IoT", Enabled: true},
+	}
+	clients := []unifi.User{
+		{Name: "nas", HwAddress: mac("00:00:00:00:00:01"), UseFixedIP: true, FixedIP: net.ParseIP("192.168.1.10"), NetworkID: "lan"},
+		{Name: "printer", HwAddress: mac("00:00:00:00:00:02"), UseFixedIP: true, FixedIP: net.ParseIP("192.168.1.9"), NetworkID: "lan"},
+		{Name: "camera", HwAddress: mac("00:00:00:00:00:03"), UseFixedIP: true, FixedIP: net.ParseIP("192.168.2.10"), NetworkID: "iot"},
+		{Name: "Laptop", HwAddress: mac("00:00:00:00:00:04"), FixedIP: net.ParseIP("192.168.1.20"), NetworkID: "lan"},
+		{Name: "my tv", HwAddress: mac("00:00:00:00:00:05"), UseFixedIP: true, FixedIP: net.ParseIP("192.168.2.11"), NetworkID: "iot"},
+		{Name: "camera", HwAddress: mac("00:00:00:00:00:06"), UseFixedIP: true, FixedIP: net.ParseIP("192.168.2.12"), NetworkID: "iot"},
+	}
+	records := []db.Record{
+		{Base: db.Base{ID: "1"}, Name: "NAS", Target: "192.168.1.10"},
+		{Base: db.Base{ID: "2"}, Name: "printer", Target: "192.168.1.50"},
+	}
+
+	nas := AdoptEntry{MAC: "00:00:00:00:00:01", Name: "nas", IP: "192.168.1.10", Network: "LAN", Action: AdoptActionExists, RecordID: "1"}
+	printer := AdoptEntry{MAC: "00:00:00:00:00:02", Name: "printer", IP: "192.168.1.9", Network: "LAN", Action: AdoptActionConflict, Reason: "a record already exists with the target 192.168.1.50", RecordID: "2"}
+	camera := AdoptEntry{MAC: "00:00:00:00:00:03", Name: "camera", IP: "192.168.2.10", Network: "IoT", Action: AdoptActionCreate}
+	tv := AdoptEntry{MAC: "00:00:00:00:00:05", Name: "my tv", IP: "192.168.2.11", Network: "IoT", Action: AdoptActionInvalid}
+	duplicate := AdoptEntry{MAC: "00:00:00:00:00:06", Name: "camera", IP: "192.168.2.12", Network: "IoT", Action: AdoptActionConflict, Reason: "a record already exists with the target 192.168.2.10"}
+
+	tests := []struct {
+		name    string
+		opts    AdoptOptions
+		want    []AdoptEntry
+		wantErr bool
+	}{
+		{name: "all", want: []AdoptEntry{nas, printer, camera, tv, duplicate}},
+		{name: "network", opts: AdoptOptions{Network: "LAN"}, want: []AdoptEntry{nas, printer}},
+		{name: "match", opts: AdoptOptions{Match: "^cam"}, want: []AdoptEntry{camera, duplicate}},
+		{name: "macs", opts: AdoptOptions{MACs: []string{"00-00-00-00-00-03"}}, want: []AdoptEntry{camera}},
+		{name: "invalid match", opts: AdoptOptions{Match: "("}, wantErr: true},
+		{name: "invalid mac", opts: AdoptOptions{MACs: []string{"foo"}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := adoptEntries(context.Background(), clients, networks, records, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("adoptEntries() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// the reason of the invalid names comes from the validation
+			for i := range got {
+				if got[i].Action == AdoptActionInvalid {
+					if got[i].Reason == "" {
+						t.Errorf("adoptEntries() entry %q has no reason", got[i].Name)
+					}
+					got[i].Reason = ""
+				}
+			}
+
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("adoptEntries() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -5,9 +5,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/rclsilver-org/usg-dns-api/db"
 	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
 )
 
+const (
+	masterTokenName   = "master"
+	overrideTokenName = "protection-override"
+)
+
 func (s *Server) AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		token := ctx.GetHeader("Authorization")
@@ -18,12 +24,33 @@ func (s *Server) AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		if utils.StringHash(token) != s.db.GetMasterToken() {
+		// only the override token can change the protected records
+		var name string
+		var override bool
+		switch hash := utils.StringHash(token); {
+		case hash == s.db.GetMasterToken():
+			name = masterTokenName
+		case s.db.GetOverrideToken() != "" && hash == s.db.GetOverrideToken():
+			name = overrideTokenName
+			override = true
+		default:
 			ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			ctx.Abort()
 			return
 		}
 
+		requestID, _ := ctx.Request.Context().Value(RequestID).(string)
+		reqCtx := db.WithActor(ctx.Request.Context(), db.Actor{
+			Name:          name,
+			RemoteAddress: getRemoteAddress(ctx.Request),
+			RequestID:     requestID,
+		})
+		if override {
+			reqCtx = db.WithProtectionOverride(reqCtx)
+		}
+
+		ctx.Request = ctx.Request.WithContext(reqCtx)
+
 		ctx.Next()
 	}
 }
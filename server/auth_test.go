@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+func TestAuthMiddleware_protectionOverride(t *testing.T) {
+	s, masterToken := newTestServer(t)
+	overrideToken := s.db.GenerateOverrideToken()
+
+	record, err := s.db.AddRecord(context.Background(), "gateway", "192.168.1.1", db.WithProtected(true))
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	do := func(token, method, body string) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(method, "/records/"+record.ID, strings.NewReader(body))
+		r.Header.Set("Authorization", token)
+		r.Header.Set("Content-Type", "application/json")
+		s.router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		body   string
+		want   int
+	}{
+		{name: "invalid token", token: "invalid", method: http.MethodGet, want: http.StatusUnauthorized},
+		{name: "master read", token: masterToken, method: http.MethodGet, want: http.StatusOK},
+		{name: "override read", token: overrideToken, method: http.MethodGet, want: http.StatusOK},
+		{name: "master update", token: masterToken, method: http.MethodPut, body: `{"name": "gateway", "target": "192.168.1.254"}`, want: http.StatusForbidden},
+		{name: "master unprotect", token: masterToken, method: http.MethodPatch, body: `{"protected": false}`, want: http.StatusForbidden},
+		{name: "master delete", token: masterToken, method: http.MethodDelete, want: http.StatusForbidden},
+		{name: "override update", token: overrideToken, method: http.MethodPut, body: `{"name": "gateway", "target": "192.168.1.254"}`, want: http.StatusOK},
+		{name: "override delete", token: overrideToken, method: http.MethodDelete, want: http.StatusNoContent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := do(tt.token, tt.method, tt.body); got != tt.want {
+				t.Errorf("%s /records/<id> = %d, want %d", tt.method, got, tt.want)
+			}
+		})
+	}
+
+	// the changes of the override token have their own actor
+	history, err := s.db.GetRecordHistory(record.ID)
+	if err != nil {
+		t.Fatalf("GetRecordHistory() error = %v", err)
+	}
+	if last := history[len(history)-1]; last.Actor != overrideTokenName {
+		t.Errorf("GetRecordHistory() actor = %q, want %q", last.Actor, overrideTokenName)
+	}
+}
@@ -2,8 +2,13 @@ package server
 
 import (
 	"fmt"
+	"text/template"
+	"time"
 
 	"github.com/ovh/configstore"
+
+	invsrc "github.com/rclsilver-org/usg-dns-api/inventory"
+	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
 )
 
 const (
@@ -11,9 +16,30 @@ const (
 	keyListenPort = "HTTP_LISTEN_PORT"
 	keyHostsFile  = "HOSTS_FILE"
 
+	keyUnifiSnapshotFile = "UNIFI_SNAPSHOT_FILE"
+	keyUnifiPriority     = "INVENTORY_UNIFI_PRIORITY"
+
+	keyHostsNetwork = "HOSTS_NETWORK"
+	keyHostsFilter  = "HOSTS_FILTER"
+
+	keyHostsGatewayName = "HOSTS_GATEWAY_NAME"
+	keyHostsSubnetName  = "HOSTS_SUBNET_NAME"
+
+	keyStaticDNSSync = "STATIC_DNS_SYNC"
+
+	keyStaleRecordsDisableAfter = "STALE_RECORDS_DISABLE_AFTER"
+
+	keyRecordsFile = "RECORDS_FILE"
+
 	defaultListenHost = "localhost"
 	defaultListenPort = 8080
 	defaultHostsFile  = "hosts"
+
+	defaultUnifiPriority = 100
+)
+
+var (
+	defaultUnifiSnapshotFile = "usg-dns-api.unifi.json"
 )
 
 type config struct {
@@ -22,6 +48,23 @@ type config struct {
 
 	HostsFile string
 
+	UnifiSnapshotFile string
+	UnifiPriority     int
+
+	Sources []invsrc.Source
+
+	Networks map[string]*networkSettings
+	Filter   *filter
+
+	GatewayTemplate *template.Template
+	SubnetTemplate  *template.Template
+
+	StaticDNSSync string
+
+	StaleRecordsDisableAfter time.Duration
+
+	RecordsFile string
+
 	Title   string
 	Version string
 
@@ -61,5 +104,126 @@ func loadConfig() (*config, error) {
 		cfg.HostsFile = hostsFile
 	}
 
+	unifiSnapshotFile, err := configstore.GetItemValue(keyUnifiSnapshotFile)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the unifi snapshot file path: %w", err)
+		}
+		cfg.UnifiSnapshotFile = defaultUnifiSnapshotFile
+	} else {
+		cfg.UnifiSnapshotFile = unifiSnapshotFile
+	}
+
+	unifiPriority, err := configstore.GetItemValueInt(keyUnifiPriority)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the unifi priority: %w", err)
+		}
+		cfg.UnifiPriority = defaultUnifiPriority
+	} else {
+		cfg.UnifiPriority = int(unifiPriority)
+	}
+
+	networks, err := configstore.Filter().Slice(keyHostsNetwork).Unmarshal(func() interface{} { return &networkSettingsConfig{} }).GetItemList()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the hosts network settings: %w", err)
+	}
+	cfg.Networks = make(map[string]*networkSettings, len(networks.Items))
+	for _, item := range networks.Items {
+		raw, err := item.Unmarshaled()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the hosts network settings: %w", err)
+		}
+
+		networkCfg := raw.(*networkSettingsConfig)
+		if networkCfg.Network == "" {
+			return nil, fmt.Errorf("invalid hosts network settings: the network name is required")
+		}
+
+		settings, err := parseNetworkSettings(networkCfg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hosts network settings for %q: %w", networkCfg.Network, err)
+		}
+		cfg.Networks[networkCfg.Network] = settings
+	}
+
+	filterItem, err := configstore.Filter().Slice(keyHostsFilter).Unmarshal(func() interface{} { return &filterConfig{} }).GetItem(keyHostsFilter)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the hosts filter: %w", err)
+		}
+	} else {
+		raw, err := filterItem.Unmarshaled()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse the hosts filter: %w", err)
+		}
+
+		filter, err := parseFilter(raw.(*filterConfig))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hosts filter: %w", err)
+		}
+		cfg.Filter = filter
+	}
+
+	gatewayName, err := configstore.GetItemValue(keyHostsGatewayName)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the gateway name template: %w", err)
+		}
+	} else {
+		tmpl, err := template.New(keyHostsGatewayName).Option("missingkey=error").Parse(gatewayName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway name template: %w", err)
+		}
+		cfg.GatewayTemplate = tmpl
+	}
+
+	subnetName, err := configstore.GetItemValue(keyHostsSubnetName)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the subnet name template: %w", err)
+		}
+	} else {
+		tmpl, err := template.New(keyHostsSubnetName).Option("missingkey=error").Parse(subnetName)
+		if err != nil {
+			return nil, fmt.Errorf("invalid subnet name template: %w", err)
+		}
+		cfg.SubnetTemplate = tmpl
+	}
+
+	staticDNSSync, err := configstore.GetItemValue(keyStaticDNSSync)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the static DNS sync mode: %w", err)
+		}
+	} else {
+		if err := validateStaticDNSSyncMode(staticDNSSync); err != nil {
+			return nil, err
+		}
+		cfg.StaticDNSSync = staticDNSSync
+	}
+
+	staleRecordsDisableAfter, err := configstore.GetItemValue(keyStaleRecordsDisableAfter)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the stale records delay: %w", err)
+		}
+	} else {
+		delay, err := utils.ParseDuration(staleRecordsDisableAfter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stale records delay: %w", err)
+		}
+		cfg.StaleRecordsDisableAfter = delay
+	}
+
+	recordsFile, err := configstore.GetItemValue(keyRecordsFile)
+	if err != nil {
+		if _, ok := err.(configstore.ErrItemNotFound); !ok {
+			return nil, fmt.Errorf("unable to get the records file: %w", err)
+		}
+	} else {
+		cfg.RecordsFile = recordsFile
+	}
+
 	return &cfg, nil
 }
@@ -6,6 +6,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/juju/errors"
 	"github.com/loopfz/gadgeto/tonic"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
 )
 
 type APIError struct {
@@ -37,6 +39,12 @@ func parseError(err error) (int, string) {
 
 		case errors.Is(err, errors.NotImplemented):
 			return http.StatusNotImplemented, err.Error()
+
+		case errors.Is(err, db.ErrVersionMismatch):
+			return http.StatusPreconditionFailed, err.Error()
+
+		case errors.Is(err, db.ErrProtected):
+			return http.StatusForbidden, "this record is protected, use the protection override token to change it"
 		}
 
 		return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
@@ -46,9 +54,25 @@ func parseError(err error) (int, string) {
 func errorHook(ctx *gin.Context, e error) (int, interface{}) {
 	code, msg := parseError(e)
 
+	// the failed batches are rendered with the result of each operation
+	var batchErr *batchError
+	if errors.As(e, &batchErr) {
+		return code, batchErr.out
+	}
+
 	err := APIError{
 		Message: msg,
 	}
 
 	return code, err
 }
+
+// statusKey overrides the success status of the route.
+const statusKey = "status"
+
+func renderHook(c *gin.Context, status int, payload interface{}) {
+	if override := c.GetInt(statusKey); override != 0 {
+		status = override
+	}
+	tonic.DefaultRenderHook(c, status, payload)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
+)
+
+func recordETag(record db.Record) string {
+	return fmt.Sprintf(`"%d"`, record.Version)
+}
+
+func listETag(value interface{}) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`"%s"`, utils.BytesHash(data)), nil
+}
+
+// parseIfMatch returns 0 when any version matches.
+func parseIfMatch(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	if strings.Contains(header, ",") {
+		return 0, errors.NewBadRequest(nil, "only one entity tag is supported in the If-Match header")
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	version, err := strconv.Atoi(tag)
+	if !ok || err != nil || version <= 0 {
+		return 0, errors.NewBadRequest(nil, "invalid If-Match header")
+	}
+
+	return version, nil
+}
+
+func matchIfNoneMatch(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
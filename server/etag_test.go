@@ -0,0 +1,51 @@
+package server
+
+import "testing"
+
+func Test_parseIfMatch(t *testing.T) {
+	tests := []struct {
+		header  string
+		want    int
+		wantErr bool
+	}{
+		{header: "", want: 0},
+		{header: "*", want: 0},
+		{header: `"3"`, want: 3},
+		{header: ` "12" `, want: 12},
+		{header: `3`, wantErr: true},
+		{header: `W/"3"`, wantErr: true},
+		{header: `"0"`, wantErr: true},
+		{header: `"3", "4"`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			got, err := parseIfMatch(tt.header)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseIfMatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseIfMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_matchIfNoneMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{header: `"abc"`, want: true},
+		{header: `W/"abc"`, want: true},
+		{header: `"def", "abc"`, want: true},
+		{header: `*`, want: true},
+		{header: `"def"`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			if got := matchIfNoneMatch(tt.header, `"abc"`); got != tt.want {
+				t.Errorf("matchIfNoneMatch() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
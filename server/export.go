@@ -0,0 +1,161 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/netip"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+const (
+	exportFormatJSON    = "json"
+	exportFormatCSV     = "csv"
+	exportFormatHosts   = "hosts"
+	exportFormatBIND    = "bind"
+	exportFormatDnsmasq = "dnsmasq"
+
+	exportScopeRecords   = "records"
+	exportScopeInventory = "inventory"
+)
+
+type exportFormat struct {
+	ContentType string
+	Extension   string
+}
+
+var exportFormats = map[string]exportFormat{
+	exportFormatJSON:    {ContentType: "application/json", Extension: "json"},
+	exportFormatCSV:     {ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+	exportFormatHosts:   {ContentType: "text/plain; charset=utf-8", Extension: "hosts"},
+	exportFormatBIND:    {ContentType: "text/dns; charset=utf-8", Extension: "zone"},
+	exportFormatDnsmasq: {ContentType: "text/plain; charset=utf-8", Extension: "conf"},
+}
+
+type exportEntry struct {
+	IP     string
+	Names  []string
+	Source string
+}
+
+func recordsExportEntries(records []db.Record) []exportEntry {
+	byTarget := map[string]*exportEntry{}
+	for _, record := range records {
+		entry, ok := byTarget[record.Target]
+		if !ok {
+			entry = &exportEntry{IP: record.Target, Source: sourceDatabase}
+			byTarget[record.Target] = entry
+		}
+		entry.Names = append(entry.Names, record.Name)
+	}
+
+	entries := make([]exportEntry, 0, len(byTarget))
+	for _, entry := range byTarget {
+		sort.Strings(entry.Names)
+		entries = append(entries, *entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		a, errA := netip.ParseAddr(entries[i].IP)
+		b, errB := netip.ParseAddr(entries[j].IP)
+		if errA != nil || errB != nil {
+			return entries[i].IP < entries[j].IP
+		}
+		return a.Less(b)
+	})
+
+	return entries
+}
+
+// inventoryExportEntries keeps the order of the hosts file, sorted by IP address.
+func inventoryExportEntries(inv *inventory) []exportEntry {
+	entries := make([]exportEntry, 0, len(inv.Hosts))
+	for _, host := range inv.Hosts {
+		entries = append(entries, exportEntry{
+			IP:     host.IP,
+			Names:  append([]string{host.HostName}, host.Aliases...),
+			Source: host.Source,
+		})
+	}
+	return entries
+}
+
+func writeExport(w io.Writer, format string, entries []exportEntry) error {
+	switch format {
+	case exportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write([]string{"name", "target", "source"}); err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			for _, name := range entry.Names {
+				if err := writer.Write([]string{name, entry.IP, entry.Source}); err != nil {
+					return err
+				}
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case exportFormatHosts:
+		for _, entry := range entries {
+			if _, err := fmt.Fprintf(w, "%s\t%s\n", entry.IP, strings.Join(entry.Names, " ")); err != nil {
+				return err
+			}
+		}
+
+	case exportFormatBIND:
+		for _, entry := range entries {
+			recordType := "A"
+			if addr, err := netip.ParseAddr(entry.IP); err == nil && addr.Is6() {
+				recordType = "AAAA"
+			}
+
+			for _, name := range entry.Names {
+				// the names with a dot are considered fully qualified
+				if strings.Contains(name, ".") {
+					name += "."
+				}
+				if _, err := fmt.Fprintf(w, "%s\tIN\t%s\t%s\n", name, recordType, entry.IP); err != nil {
+					return err
+				}
+			}
+		}
+
+	case exportFormatDnsmasq:
+		for _, entry := range entries {
+			if _, err := fmt.Fprintf(w, "host-record=%s,%s\n", strings.Join(entry.Names, ","), entry.IP); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+
+	return nil
+}
+
+func writeExportHeader(w io.Writer, format, scope string, now time.Time) error {
+	prefix := "#"
+	switch format {
+	case exportFormatJSON, exportFormatCSV:
+		return nil
+	case exportFormatBIND:
+		prefix = ";"
+	}
+
+	_, err := fmt.Fprintf(w, "%s Export of the %s of the usg-dns-api (%s)\n", prefix, scope, now.Format(time.RFC3339))
+	return err
+}
+
+func writeJSONExport(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
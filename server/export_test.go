@@ -0,0 +1,77 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+func Test_writeExport(t *testing.T) {
+	entries := recordsExportEntries([]db.Record{
+		{Name: "nas.home.arpa", Target: "192.168.1.10", Enabled: true},
+		{Name: "printer", Target: "192.168.1.9", Enabled: true},
+		{Name: "nas", Target: "192.168.1.10", Enabled: true},
+	})
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: exportFormatCSV, want: "name,target,source\nprinter,192.168.1.9,database\nnas,192.168.1.10,database\nnas.home.arpa,192.168.1.10,database\n"},
+		{format: exportFormatHosts, want: "192.168.1.9\tprinter\n192.168.1.10\tnas nas.home.arpa\n"},
+		{format: exportFormatBIND, want: "printer\tIN\tA\t192.168.1.9\nnas\tIN\tA\t192.168.1.10\nnas.home.arpa.\tIN\tA\t192.168.1.10\n"},
+		{format: exportFormatDnsmasq, want: "host-record=printer,192.168.1.9\nhost-record=nas,nas.home.arpa,192.168.1.10\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buffer := bytes.NewBuffer(nil)
+			if err := writeExport(buffer, tt.format, entries); err != nil {
+				t.Fatalf("writeExport() error = %v", err)
+			}
+			if got := buffer.String(); got != tt.want {
+				t.Errorf("writeExport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_exportGet_includeDisabled(t *testing.T) {
+	s, token := newTestServer(t)
+
+	if _, err := s.db.AddRecord(context.Background(), "nas", "192.168.1.10"); err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	if _, err := s.db.AddRecord(context.Background(), "old-nas", "192.168.1.11", db.WithEnabled(false)); err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "format=hosts", want: "192.168.1.10\tnas\n"},
+		{query: "format=hosts&include_disabled=true", want: "192.168.1.10\tnas\n192.168.1.11\told-nas\n"},
+		{query: "format=json", want: `"name": "nas"`},
+		{query: "format=json&include_disabled=true", want: `"name": "old-nas"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/export?"+tt.query, nil)
+			r.Header.Set("Authorization", token)
+			s.router.ServeHTTP(w, r)
+
+			if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("GET /export?%s = %d %q, want %q", tt.query, w.Code, w.Body.String(), tt.want)
+			}
+			if strings.Contains(w.Body.String(), "old-nas") != strings.Contains(tt.query, "include_disabled") {
+				t.Errorf("GET /export?%s = %q", tt.query, w.Body.String())
+			}
+		})
+	}
+}
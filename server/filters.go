@@ -0,0 +1,158 @@
+package server
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+type filterRulesConfig struct {
+	Networks []string `json:"networks"`
+	Purposes []string `json:"purposes"`
+	OUIs     []string `json:"ouis"`
+	Names    string   `json:"names"`
+}
+
+type filterConfig struct {
+	Include  filterRulesConfig `json:"include"`
+	Exclude  filterRulesConfig `json:"exclude"`
+	DenyMACs []string          `json:"deny-macs"`
+}
+
+type filterRules struct {
+	Networks []string
+	Purposes []string
+	OUIs     []string
+	Names    *regexp.Regexp
+}
+
+type filter struct {
+	Include  filterRules
+	Exclude  filterRules
+	DenyMACs []string
+}
+
+func parseFilterRules(raw filterRulesConfig) (filterRules, error) {
+	rules := filterRules{
+		Networks: raw.Networks,
+		Purposes: raw.Purposes,
+	}
+
+	for _, oui := range raw.OUIs {
+		prefix, err := normalizeMACPrefix(oui)
+		if err != nil {
+			return filterRules{}, err
+		}
+		rules.OUIs = append(rules.OUIs, prefix)
+	}
+
+	if raw.Names != "" {
+		re, err := regexp.Compile(raw.Names)
+		if err != nil {
+			return filterRules{}, fmt.Errorf("invalid names regex: %w", err)
+		}
+		rules.Names = re
+	}
+
+	return rules, nil
+}
+
+func parseFilter(raw *filterConfig) (*filter, error) {
+	include, err := parseFilterRules(raw.Include)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include rules: %w", err)
+	}
+
+	exclude, err := parseFilterRules(raw.Exclude)
+	if err != nil {
+		return nil, fmt.Errorf("invalid exclude rules: %w", err)
+	}
+
+	f := &filter{
+		Include: include,
+		Exclude: exclude,
+	}
+
+	for _, mac := range raw.DenyMACs {
+		hwAddr, err := net.ParseMAC(mac)
+		if err != nil {
+			return nil, fmt.Errorf("invalid denied MAC address %q: %w", mac, err)
+		}
+		f.DenyMACs = append(f.DenyMACs, hwAddr.String())
+	}
+
+	return f, nil
+}
+
+// normalizeMACPrefix converts e.g. B8-27-EB or b827eb to b8:27:eb.
+func normalizeMACPrefix(prefix string) (string, error) {
+	digits := strings.ToLower(strings.NewReplacer(":", "", "-", "", ".", "").Replace(prefix))
+	if _, err := hex.DecodeString(digits); err != nil || digits == "" || len(digits) > 12 {
+		return "", fmt.Errorf("invalid MAC prefix %q", prefix)
+	}
+
+	parts := make([]string, 0, len(digits)/2)
+	for i := 0; i < len(digits); i += 2 {
+		parts = append(parts, digits[i:i+2])
+	}
+
+	return strings.Join(parts, ":"), nil
+}
+
+// network returns the reason why the network is excluded, if any.
+func (f *filter) network(network unifi.NetworkConf) string {
+	if f == nil {
+		return ""
+	}
+
+	if len(f.Include.Networks) > 0 && !slices.Contains(f.Include.Networks, network.Name) {
+		return "network not included"
+	}
+	if slices.Contains(f.Exclude.Networks, network.Name) {
+		return "network excluded"
+	}
+	if len(f.Include.Purposes) > 0 && !slices.Contains(f.Include.Purposes, network.Purpose) {
+		return fmt.Sprintf("network purpose %q not included", network.Purpose)
+	}
+	if slices.Contains(f.Exclude.Purposes, network.Purpose) {
+		return fmt.Sprintf("network purpose %q excluded", network.Purpose)
+	}
+
+	return ""
+}
+
+func (f *filter) client(client unifi.User, name string) string {
+	return f.host(client.HwAddress, name)
+}
+
+// host ignores the MAC address rules when the MAC address is unknown.
+func (f *filter) host(hwAddr net.HardwareAddr, name string) string {
+	if f == nil {
+		return ""
+	}
+
+	if mac := hwAddr.String(); mac != "" {
+		if slices.Contains(f.DenyMACs, mac) {
+			return "MAC address denied"
+		}
+		if len(f.Include.OUIs) > 0 && !slices.ContainsFunc(f.Include.OUIs, func(prefix string) bool { return strings.HasPrefix(mac, prefix) }) {
+			return "MAC prefix not included"
+		}
+		if slices.ContainsFunc(f.Exclude.OUIs, func(prefix string) bool { return strings.HasPrefix(mac, prefix) }) {
+			return "MAC prefix excluded"
+		}
+	}
+	if f.Include.Names != nil && !f.Include.Names.MatchString(name) {
+		return "name not included"
+	}
+	if f.Exclude.Names != nil && f.Exclude.Names.MatchString(name) {
+		return "name excluded"
+	}
+
+	return ""
+}
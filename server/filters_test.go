@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+func Test_normalizeMACPrefix(t *testing.T) {
+	tests := []struct {
+		prefix  string
+		want    string
+		wantErr bool
+	}{
+		{prefix: "b8:27:eb", want: "b8:27:eb"},
+		{prefix: "B8-27-EB", want: "b8:27:eb"},
+		{prefix: "b827eb", want: "b8:27:eb"},
+		{prefix: "b827e", wantErr: true},
+		{prefix: "zz:27:eb", wantErr: true},
+		{prefix: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			got, err := normalizeMACPrefix(tt.prefix)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("normalizeMACPrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("normalizeMACPrefix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_filter_client(t *testing.T) {
+	f, err := parseFilter(&filterConfig{
+		Exclude: filterRulesConfig{
+			OUIs:  []string{"b8:27:eb"},
+			Names: "^tmp-",
+		},
+		DenyMACs: []string{"00:11:22:33:44:55"},
+	})
+	if err != nil {
+		t.Fatalf("parseFilter() error = %v", err)
+	}
+
+	tests := []struct {
+		mac      string
+		name     string
+		excluded bool
+	}{
+		{mac: "00:11:22:33:44:66", name: "nas", excluded: false},
+		{mac: "00:11:22:33:44:55", name: "nas", excluded: true},
+		{mac: "b8:27:eb:00:00:01", name: "pi", excluded: true},
+		{mac: "00:11:22:33:44:66", name: "tmp-laptop", excluded: true},
+		// the hosts of the inventory sources may have no MAC address
+		{mac: "", name: "nas", excluded: false},
+		{mac: "", name: "tmp-laptop", excluded: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mac+"/"+tt.name, func(t *testing.T) {
+			hwAddr, _ := net.ParseMAC(tt.mac)
+			if reason := f.client(unifi.User{HwAddress: hwAddr}, tt.name); (reason != "") != tt.excluded {
+				t.Errorf("client() reason = %q, excluded %v", reason, tt.excluded)
+			}
+		})
+	}
+}
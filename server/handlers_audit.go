@@ -0,0 +1,52 @@
+package server
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+type auditListIn struct {
+	Since    string `query:"since" description:"RFC 3339 timestamp"`
+	Until    string `query:"until" description:"RFC 3339 timestamp"`
+	RecordID string `query:"record_id"`
+	Limit    int    `query:"limit" default:"100" description:"Maximum number of entries, the most recent ones, 0 for all of them"`
+}
+
+func (s *Server) auditList(c *gin.Context, in *auditListIn) ([]db.AuditEntry, error) {
+	var filter db.AuditFilter
+
+	if in.Since != "" {
+		since, err := time.Parse(time.RFC3339, in.Since)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, "invalid since value")
+		}
+		filter.Since = since
+	}
+
+	if in.Until != "" {
+		until, err := time.Parse(time.RFC3339, in.Until)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, "invalid until value")
+		}
+		filter.Until = until
+	}
+
+	filter.RecordID = in.RecordID
+
+	if in.Limit < 0 {
+		return nil, errors.NewBadRequest(nil, "invalid limit value")
+	}
+	filter.Limit = in.Limit
+
+	entries, err := s.db.GetAuditEntries(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading the audit log: %w", err)
+	}
+
+	return entries, nil
+}
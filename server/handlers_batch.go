@@ -0,0 +1,131 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+const (
+	batchStatusApplied = "applied"
+	batchStatusFailed  = "failed"
+	batchStatusSkipped = "skipped"
+)
+
+type recordBatchOperationIn struct {
+	Operation string `json:"operation" enum:"create,update,delete"`
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Target    string `json:"target,omitempty"`
+	Version   int    `json:"version,omitempty"`
+
+	recordAttributesIn
+}
+
+type recordBatchIn struct {
+	Operations []recordBatchOperationIn `json:"operations" validate:"required"`
+}
+
+type recordBatchResultOut struct {
+	Operation string     `json:"operation"`
+	Status    string     `json:"status"`
+	Record    *db.Record `json:"record,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+type recordBatchOut struct {
+	Applied bool                   `json:"applied"`
+	Results []recordBatchResultOut `json:"results"`
+}
+
+// batchError is rendered with the result of each operation.
+type batchError struct {
+	err error
+	out *recordBatchOut
+}
+
+func (e *batchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *batchError) Unwrap() error {
+	return e.err
+}
+
+func batchOperationError(err error) error {
+	switch err {
+	case db.ErrNotFound:
+		return errors.NewNotFound(nil, "no record found with this ID")
+	case db.ErrManaged:
+		return errors.NewForbidden(nil, "this record is managed by a declarative file")
+	case db.ErrAlreadyExists:
+		return errors.NewAlreadyExists(nil, "a record already exists with this name")
+	}
+	return err
+}
+
+func (s *Server) recordBatch(c *gin.Context, in *recordBatchIn) (*recordBatchOut, error) {
+	if len(in.Operations) == 0 {
+		return nil, errors.NewBadRequest(nil, "no operation in the batch")
+	}
+
+	operations := make([]db.BatchOperation, len(in.Operations))
+	for i, op := range in.Operations {
+		operations[i] = db.BatchOperation{
+			Operation: op.Operation,
+			ID:        op.ID,
+			Name:      op.Name,
+			Target:    op.Target,
+			Version:   op.Version,
+			Options:   op.options(),
+		}
+	}
+
+	results, err := s.db.ApplyBatch(c, operations)
+
+	out := &recordBatchOut{
+		Applied: err == nil,
+		Results: make([]recordBatchResultOut, len(results)),
+	}
+
+	var failed error
+	for i, result := range results {
+		res := recordBatchResultOut{Operation: operations[i].Operation}
+
+		switch {
+		case result.Err != nil:
+			opErr := batchOperationError(result.Err)
+			_, res.Error = parseError(opErr)
+			res.Status = batchStatusFailed
+			if failed == nil {
+				failed = errors.Annotatef(opErr, "operation %d", i)
+			}
+
+		case err != nil:
+			res.Status = batchStatusSkipped
+
+		default:
+			res.Status = batchStatusApplied
+			if operations[i].Operation != db.BatchOperationDelete {
+				record := result.Record
+				res.Record = &record
+			}
+		}
+
+		out.Results[i] = res
+	}
+
+	if err != nil {
+		if failed == nil {
+			failed = fmt.Errorf("error while applying the batch: %w", err)
+		}
+		return nil, &batchError{err: failed, out: out}
+	}
+
+	s.runTask(c)
+
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+type exportIn struct {
+	Format string `query:"format" default:"json" enum:"json,csv,hosts,bind,dnsmasq"`
+	Scope  string `query:"scope" default:"records" enum:"records,inventory"`
+
+	IncludeDisabled bool `query:"include_disabled" description:"Include the disabled records, left out by default like in the hosts file"`
+}
+
+func (s *Server) exportGet(c *gin.Context, in *exportIn) error {
+	format, ok := exportFormats[in.Format]
+	if !ok {
+		return errors.NewBadRequest(nil, fmt.Sprintf("unsupported format %q", in.Format))
+	}
+
+	var (
+		entries []exportEntry
+		value   interface{}
+	)
+
+	switch in.Scope {
+	case exportScopeRecords:
+		records := s.db.GetRecords()
+		if !in.IncludeDisabled {
+			records = slices.DeleteFunc(records, func(r db.Record) bool { return !r.Enabled })
+		}
+		entries = recordsExportEntries(records)
+		value = records
+
+	case exportScopeInventory:
+		inv := s.getInventory()
+		if inv == nil {
+			return errors.NewNotFound(nil, "the inventory has not been generated yet")
+		}
+		entries = inventoryExportEntries(inv)
+		value = inv
+
+	default:
+		return errors.NewBadRequest(nil, fmt.Sprintf("unsupported scope %q", in.Scope))
+	}
+
+	c.Header("Content-Type", format.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="usg-dns-api-%s.%s"`, in.Scope, format.Extension))
+	c.Status(http.StatusOK)
+
+	// the response is streamed, an error can only be logged
+	err := writeExportHeader(c.Writer, in.Format, in.Scope, time.Now())
+	if err == nil {
+		if in.Format == exportFormatJSON {
+			err = writeJSONExport(c.Writer, value)
+		} else {
+			err = writeExport(c.Writer, in.Format, entries)
+		}
+	}
+	if err != nil {
+		logrus.WithContext(c).WithError(err).Warning("unable to write the export")
+	}
+
+	return nil
+}
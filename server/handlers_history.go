@@ -0,0 +1,73 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+type recordHistoryIn struct {
+	ID string `path:"record_id"`
+}
+
+func (s *Server) recordHistory(c *gin.Context, in *recordHistoryIn) ([]db.RecordVersion, error) {
+	history, err := s.db.GetRecordHistory(in.ID)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no record found with this ID")
+		}
+		return nil, fmt.Errorf("error while fetching the history of the record: %w", err)
+	}
+
+	return history, nil
+}
+
+type recordRevertIn struct {
+	ID      string `path:"record_id"`
+	Version int    `query:"version" validate:"required"`
+}
+
+func (s *Server) recordRevert(c *gin.Context, in *recordRevertIn) (*db.Record, error) {
+	rec, err := s.db.RevertRecord(c, in.ID, in.Version)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no record found with this ID")
+		} else if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
+		} else if err == db.ErrAlreadyExists {
+			return nil, errors.NewAlreadyExists(nil, "a record already exists with the name of this version")
+		}
+		return nil, fmt.Errorf("error while reverting the record: %w", err)
+	}
+	c.Header("ETag", recordETag(rec))
+
+	return &rec, nil
+}
+
+func (s *Server) recordDeletedList(c *gin.Context) ([]db.Tombstone, error) {
+	return s.db.GetDeletedRecords(), nil
+}
+
+type recordRestoreIn struct {
+	ID string `path:"record_id"`
+}
+
+func (s *Server) recordRestore(c *gin.Context, in *recordRestoreIn) (*db.Record, error) {
+	rec, err := s.db.RestoreRecord(c, in.ID)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no deleted record found with this ID")
+		} else if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
+		} else if err == db.ErrAlreadyExists {
+			return nil, errors.NewAlreadyExists(nil, "a record already exists with this name")
+		}
+		return nil, fmt.Errorf("error while restoring the record: %w", err)
+	}
+	c.Header("ETag", recordETag(rec))
+
+	return &rec, nil
+}
@@ -0,0 +1,15 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+)
+
+func (s *Server) inventoryGet(c *gin.Context) (*inventory, error) {
+	inv := s.getInventory()
+	if inv == nil {
+		return nil, errors.NewNotFound(nil, "the inventory has not been generated yet")
+	}
+
+	return inv, nil
+}
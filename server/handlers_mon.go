@@ -19,3 +19,13 @@ func (s *Server) monPing(c *gin.Context) (*pingOut, error) {
 		Status: pingOutStatusOK,
 	}, nil
 }
+
+type statusOut struct {
+	Unifi unifiStatus `json:"unifi"`
+}
+
+func (s *Server) monStatus(c *gin.Context) (*statusOut, error) {
+	return &statusOut{
+		Unifi: s.getUnifiStatus(),
+	}, nil
+}
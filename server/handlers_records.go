@@ -1,16 +1,80 @@
 package server
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/juju/errors"
 
 	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
 )
 
-func (s *Server) recordList(c *gin.Context) ([]db.Record, error) {
-	return s.db.GetRecords(), nil
+type recordListIn struct {
+	IfNoneMatch string `header:"If-None-Match"`
+
+	Name          string `query:"name" description:"Case-insensitive glob pattern of the names (e.g. ci-*)"`
+	Target        string `query:"target" description:"IP address or CIDR of the targets"`
+	Selector      string `query:"selector" description:"Label selector (e.g. env=ci,team!=net,owner,!temporary)"`
+	CreatedAfter  string `query:"created_after" description:"RFC 3339 timestamp"`
+	CreatedBefore string `query:"created_before" description:"RFC 3339 timestamp"`
+	Sort          string `query:"sort" description:"Sort key (name, target, created_at, updated_at), prefixed with - for the descending order" default:"name"`
+	Limit         int    `query:"limit" description:"Maximum number of records, 0 for all of them"`
+	Cursor        string `query:"cursor" description:"Cursor of the next page, from the X-Next-Cursor header"`
+}
+
+func (s *Server) recordList(c *gin.Context, in *recordListIn) ([]db.Record, error) {
+	query, err := newRecordQuery(in)
+	if err != nil {
+		return nil, err
+	}
+
+	records, total, next := query.apply(s.db.GetRecords())
+	c.Header(HeaderTotalCount, strconv.Itoa(total))
+	if next != "" {
+		c.Header(HeaderNextCursor, next)
+	}
+
+	etag, err := listETag(records)
+	if err != nil {
+		return nil, fmt.Errorf("error while computing the ETag: %w", err)
+	}
+	c.Header("ETag", etag)
+
+	if in.IfNoneMatch != "" && matchIfNoneMatch(in.IfNoneMatch, etag) {
+		c.AbortWithStatus(http.StatusNotModified)
+		return nil, nil
+	}
+
+	return records, nil
+}
+
+type recordDeleteSelectedIn struct {
+	Selector string `query:"selector" validate:"required" description:"Label selector of the deleted records (e.g. env=ci)"`
+}
+
+func (s *Server) recordDeleteSelected(c *gin.Context, in *recordDeleteSelectedIn) ([]db.Record, error) {
+	selector, err := db.ParseSelector(in.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	records, err := s.db.DeleteRecords(c, selector)
+	if err != nil {
+		return nil, fmt.Errorf("error while deleting the records: %w", err)
+	}
+
+	if len(records) > 0 {
+		s.runTask(c)
+	}
+
+	return records, nil
 }
 
 type recordGetIn struct {
@@ -25,59 +89,348 @@ func (s *Server) recordGet(c *gin.Context, in *recordGetIn) (*db.Record, error)
 		}
 		return nil, fmt.Errorf("error while fetching the record: %w", err)
 	}
+	c.Header("ETag", recordETag(rec))
 
 	return &rec, nil
 }
 
+// recordAttributesIn keeps the current or default value of the attributes which are not given.
+type recordAttributesIn struct {
+	Labels      map[string]string `json:"labels,omitempty"`
+	Description *string           `json:"description,omitempty"`
+	Enabled     *bool             `json:"enabled,omitempty"`
+	Protected   *bool             `json:"protected,omitempty"`
+}
+
+func (in recordAttributesIn) options() []db.RecordOption {
+	var opts []db.RecordOption
+	if in.Labels != nil {
+		opts = append(opts, db.WithLabels(in.Labels))
+	}
+	if in.Description != nil {
+		opts = append(opts, db.WithDescription(*in.Description))
+	}
+	if in.Enabled != nil {
+		opts = append(opts, db.WithEnabled(*in.Enabled))
+	}
+	if in.Protected != nil {
+		opts = append(opts, db.WithProtected(*in.Protected))
+	}
+	return opts
+}
+
 type recordAddIn struct {
 	Name   string `json:"name"`
 	Target string `json:"target"`
+
+	recordAttributesIn
 }
 
 func (s *Server) recordAdd(c *gin.Context, in *recordAddIn) (*db.Record, error) {
-	rec, err := s.db.AddRecord(in.Name, in.Target)
+	rec, err := s.db.AddRecord(c, in.Name, in.Target, in.options()...)
 	if err != nil {
 		if err == db.ErrAlreadyExists {
 			return nil, errors.NewAlreadyExists(err, "this record already exists")
 		}
 		return nil, fmt.Errorf("error while adding the record: %w", err)
 	}
+	c.Header("ETag", recordETag(rec))
 
 	return &rec, nil
 }
 
 type recordUpdateIn struct {
-	ID     string `path:"record_id"`
-	Name   string `json:"name"`
-	Target string `json:"target"`
+	ID      string `path:"record_id"`
+	IfMatch string `header:"If-Match"`
+	Name    string `json:"name"`
+	Target  string `json:"target"`
+
+	recordAttributesIn
 }
 
 func (s *Server) recordUpdate(c *gin.Context, in *recordUpdateIn) (*db.Record, error) {
-	rec, err := s.db.UpdateRecord(in.ID, in.Name, in.Target)
+	version, err := parseIfMatch(in.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := s.db.UpdateRecord(c, in.ID, in.Name, in.Target, version, in.options()...)
 	if err != nil {
 		if err == db.ErrNotFound {
 			return nil, errors.NewNotFound(nil, "no record found with this ID")
+		} else if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
 		} else if err == db.ErrAlreadyExists {
 			return nil, errors.NewAlreadyExists(nil, "a record already exists with those parameters")
 		}
 		return nil, fmt.Errorf("error while updating the record: %w", err)
 	}
+	c.Header("ETag", recordETag(rec))
+
+	return &rec, nil
+}
+
+// recordMergePatch is a JSON merge patch (RFC 7396) of a record.
+type recordMergePatch struct {
+	Name        *string            `json:"name,omitempty"`
+	Target      *string            `json:"target,omitempty"`
+	Labels      map[string]*string `json:"labels,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	Enabled     *bool              `json:"enabled,omitempty"`
+	Protected   *bool              `json:"protected,omitempty"`
+
+	// present are the fields of the patch, including the null ones
+	present map[string]bool
+}
+
+func (p *recordMergePatch) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	type plain recordMergePatch
+	if err := json.Unmarshal(data, (*plain)(p)); err != nil {
+		return err
+	}
+
+	p.present = map[string]bool{}
+	for field := range fields {
+		p.present[field] = true
+	}
+
+	return nil
+}
+
+func (p *recordMergePatch) dbPatch() (db.RecordPatch, error) {
+	for field := range p.present {
+		switch field {
+		case "name", "target", "labels", "description", "enabled", "protected":
+		default:
+			return db.RecordPatch{}, errors.NewBadRequest(nil, fmt.Sprintf("the field %q cannot be patched", field))
+		}
+	}
+
+	if p.present["name"] && p.Name == nil {
+		return db.RecordPatch{}, errors.NewBadRequest(nil, "the name cannot be removed")
+	}
+	if p.present["target"] && p.Target == nil {
+		return db.RecordPatch{}, errors.NewBadRequest(nil, "the target cannot be removed")
+	}
+	if p.present["enabled"] && p.Enabled == nil {
+		return db.RecordPatch{}, errors.NewBadRequest(nil, "the enabled flag cannot be removed")
+	}
+	if p.present["protected"] && p.Protected == nil {
+		return db.RecordPatch{}, errors.NewBadRequest(nil, "the protected flag cannot be removed")
+	}
+
+	patch := db.RecordPatch{
+		Name:   p.Name,
+		Target: p.Target,
+		Labels: p.Labels,
+
+		// a null labels object removes all the labels
+		ClearLabels: p.present["labels"] && p.Labels == nil,
+
+		Description: p.Description,
+		Enabled:     p.Enabled,
+		Protected:   p.Protected,
+	}
+
+	// a null description removes it
+	if p.present["description"] && p.Description == nil {
+		empty := ""
+		patch.Description = &empty
+	}
+
+	return patch, nil
+}
+
+const contentTypeMergePatch = "application/merge-patch+json"
+
+// requireContentType rejects the other content types before tonic binds the body.
+func requireContentType(contentTypes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !slices.Contains(contentTypes, c.ContentType()) {
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, APIError{
+				Message: fmt.Sprintf("unsupported content type %q, expected %s", c.ContentType(), strings.Join(contentTypes, " or ")),
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+type recordPatchIn struct {
+	ID      string `path:"record_id"`
+	IfMatch string `header:"If-Match"`
+
+	recordMergePatch
+}
+
+func (s *Server) recordPatch(c *gin.Context, in *recordPatchIn) (*db.Record, error) {
+	version, err := parseIfMatch(in.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := in.dbPatch()
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := s.db.PatchRecord(c, in.ID, patch, version)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no record found with this ID")
+		} else if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
+		} else if err == db.ErrAlreadyExists {
+			return nil, errors.NewAlreadyExists(nil, "a record already exists with those parameters")
+		}
+		return nil, fmt.Errorf("error while patching the record: %w", err)
+	}
+	c.Header("ETag", recordETag(rec))
 
 	return &rec, nil
 }
 
 type recordDeleteIn struct {
-	ID string `path:"record_id"`
+	ID      string `path:"record_id"`
+	IfMatch string `header:"If-Match"`
 }
 
 func (s *Server) recordDelete(c *gin.Context, in *recordDeleteIn) error {
+	version, err := parseIfMatch(in.IfMatch)
+	if err != nil {
+		return err
+	}
 
-	if err := s.db.DeleteRecord(in.ID); err != nil {
+	if err := s.db.DeleteRecord(c, in.ID, version); err != nil {
 		if err == db.ErrNotFound {
 			return errors.NewNotFound(nil, "no record found with this ID")
+		} else if err == db.ErrManaged {
+			return errors.NewForbidden(nil, "this record is managed by a declarative file")
 		}
 		return fmt.Errorf("error while deleting the record: %w", err)
 	}
 
 	return nil
 }
+
+type recordByNameIn struct {
+	Name string `path:"name"`
+}
+
+func (s *Server) recordGetByName(c *gin.Context, in *recordByNameIn) (*db.Record, error) {
+	rec, err := s.db.GetRecordByName(in.Name)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no record found with this name")
+		}
+		return nil, fmt.Errorf("error while fetching the record: %w", err)
+	}
+	c.Header("ETag", recordETag(rec))
+
+	return &rec, nil
+}
+
+type recordPutByNameIn struct {
+	Name    string `path:"name"`
+	IfMatch string `header:"If-Match"`
+	Target  string `json:"target"`
+
+	recordAttributesIn
+}
+
+func (s *Server) recordPutByName(c *gin.Context, in *recordPutByNameIn) (*db.Record, error) {
+	version, err := parseIfMatch(in.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, created, err := s.db.PutRecordByName(c, in.Name, in.Target, version, in.options()...)
+	if err != nil {
+		if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
+		}
+		return nil, fmt.Errorf("error while putting the record: %w", err)
+	}
+	c.Header("ETag", recordETag(rec))
+
+	if created {
+		c.Set(statusKey, http.StatusCreated)
+	}
+
+	return &rec, nil
+}
+
+type recordDeleteByNameIn struct {
+	Name    string `path:"name"`
+	IfMatch string `header:"If-Match"`
+}
+
+func (s *Server) recordDeleteByName(c *gin.Context, in *recordDeleteByNameIn) (*db.Record, error) {
+	version, err := parseIfMatch(in.IfMatch)
+	if err != nil {
+		return nil, err
+	}
+
+	rec, err := s.db.DeleteRecordByName(c, in.Name, version)
+	if err != nil {
+		if err == db.ErrNotFound {
+			return nil, errors.NewNotFound(nil, "no record found with this name")
+		} else if err == db.ErrManaged {
+			return nil, errors.NewForbidden(nil, "this record is managed by a declarative file")
+		}
+		return nil, fmt.Errorf("error while deleting the record: %w", err)
+	}
+
+	return &rec, nil
+}
+
+type recordStaleIn struct {
+	OlderThan      string `query:"older_than" default:"30d"`
+	IncludeUnknown bool   `query:"include_unknown"`
+}
+
+type recordStaleOut struct {
+	db.Record
+
+	LastSeen *time.Time `json:"last_seen"`
+	MAC      string     `json:"mac,omitempty"`
+}
+
+func (s *Server) recordStale(c *gin.Context, in *recordStaleIn) ([]recordStaleOut, error) {
+	olderThan, err := utils.ParseDuration(in.OlderThan)
+	if err != nil {
+		return nil, errors.NewBadRequest(err, "invalid older_than value")
+	}
+
+	inv := s.getInventory()
+	if inv == nil {
+		return nil, errors.NewNotFound(nil, "the inventory has not been generated yet")
+	}
+
+	stale := []recordStaleOut{}
+	for _, record := range s.db.GetRecords() {
+		activity, ok := inv.activity[record.Target]
+		if !ok {
+			if in.IncludeUnknown {
+				stale = append(stale, recordStaleOut{Record: record})
+			}
+			continue
+		}
+
+		if time.Since(activity.LastSeen) > olderThan {
+			lastSeen := activity.LastSeen
+			stale = append(stale, recordStaleOut{
+				Record:   record,
+				LastSeen: &lastSeen,
+				MAC:      activity.MAC,
+			})
+		}
+	}
+
+	return stale, nil
+}
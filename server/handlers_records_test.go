@@ -0,0 +1,118 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func Test_recordMergePatch(t *testing.T) {
+	tests := []struct {
+		body    string
+		name    string
+		target  string
+		wantErr bool
+	}{
+		{body: `{}`},
+		{body: `{"target": "192.168.1.10"}`, target: "192.168.1.10"},
+		{body: `{"name": "nas", "target": "192.168.1.10"}`, name: "nas", target: "192.168.1.10"},
+		{body: `{"name": null}`, wantErr: true},
+		{body: `{"version": 2}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.body, func(t *testing.T) {
+			var p recordMergePatch
+			if err := json.Unmarshal([]byte(tt.body), &p); err != nil {
+				t.Fatalf("json.Unmarshal() error = %v", err)
+			}
+
+			patch, err := p.dbPatch()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("dbPatch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+
+			if (patch.Name == nil) != (tt.name == "") || patch.Name != nil && *patch.Name != tt.name {
+				t.Errorf("dbPatch().Name = %v, want %q", patch.Name, tt.name)
+			}
+			if (patch.Target == nil) != (tt.target == "") || patch.Target != nil && *patch.Target != tt.target {
+				t.Errorf("dbPatch().Target = %v, want %q", patch.Target, tt.target)
+			}
+		})
+	}
+}
+
+func Test_recordPutByName(t *testing.T) {
+	s, token := newTestServer(t)
+	ts := httptest.NewServer(s.router)
+	defer ts.Close()
+
+	tests := []struct {
+		body string
+		want int
+	}{
+		{body: `{"target": "192.168.1.10"}`, want: http.StatusCreated},
+		{body: `{"target": "192.168.1.11"}`, want: http.StatusOK},
+		{body: `{"target": "192.168.1.11"}`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodPut, ts.URL+"/records/by-name/nas", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", token)
+		req.Header.Set("Content-Type", "application/json")
+
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("PUT /records/by-name/nas error = %v", err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tt.want {
+			t.Errorf("PUT /records/by-name/nas status = %d, want %d", res.StatusCode, tt.want)
+		}
+		if got := res.Header.Get("Content-Type"); !strings.HasPrefix(got, "application/json") {
+			t.Errorf("PUT /records/by-name/nas Content-Type = %q, want JSON", got)
+		}
+	}
+}
+
+func Test_recordPatch_contentType(t *testing.T) {
+	s, token := newTestServer(t)
+
+	record, err := s.db.AddRecord(context.Background(), "nas", "192.168.1.10")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	tests := []struct {
+		contentType string
+		want        int
+	}{
+		{contentType: "application/merge-patch+json", want: http.StatusOK},
+		{contentType: "application/json; charset=utf-8", want: http.StatusOK},
+		{contentType: "text/plain", want: http.StatusUnsupportedMediaType},
+		{contentType: "", want: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPatch, "/records/"+record.ID, strings.NewReader(`{"target": "192.168.1.11"}`))
+			r.Header.Set("Authorization", token)
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			s.router.ServeHTTP(w, r)
+
+			if w.Code != tt.want {
+				t.Errorf("PATCH /records/<id> = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
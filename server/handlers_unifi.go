@@ -0,0 +1,159 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+type reservationSetIn struct {
+	MAC       string `path:"mac"`
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	NetworkID string `json:"network_id"`
+}
+
+type reservationOut struct {
+	ID        string `json:"id"`
+	MAC       string `json:"mac"`
+	Name      string `json:"name"`
+	IP        string `json:"ip"`
+	NetworkID string `json:"network_id"`
+}
+
+func (s *Server) reservationSet(c *gin.Context, in *reservationSetIn) (*reservationOut, error) {
+	hwAddr, err := net.ParseMAC(in.MAC)
+	if err != nil {
+		return nil, errors.NewBadRequest(err, "invalid MAC address")
+	}
+
+	if err := db.ValidateName(in.Name); err != nil {
+		return nil, err
+	}
+
+	ip := net.ParseIP(in.IP).To4()
+	if ip == nil {
+		return nil, errors.NewBadRequest(nil, "invalid IPv4 address")
+	}
+
+	if err := s.unifi.Login(c); err != nil {
+		return nil, fmt.Errorf("unable to login to the unifi-controller API: %w", err)
+	}
+
+	networks, err := s.unifi.GetNetworks(c)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the networks list: %w", err)
+	}
+
+	update := unifi.UserUpdate{
+		Name:       in.Name,
+		UseFixedIP: true,
+		FixedIP:    ip.String(),
+		NetworkID:  in.NetworkID,
+	}
+
+	if in.NetworkID != "" && !slices.ContainsFunc(networks, func(n unifi.NetworkConf) bool { return n.ID == in.NetworkID }) {
+		return nil, errors.NewNotFound(nil, "no network found with this ID")
+	}
+
+	network, ok := unifi.ClientNetwork(unifi.User{FixedIP: ip, NetworkID: in.NetworkID}, networks)
+	if !ok {
+		return nil, errors.NewBadRequest(nil, "no enabled network found for this IP address")
+	}
+	if network.IpSubnet == nil || !network.IpSubnet.Contains(ip) {
+		return nil, errors.NewBadRequest(nil, fmt.Sprintf("the IP address is not part of the network %q", network.Name))
+	}
+	update.NetworkID = network.ID
+
+	clients, err := s.unifi.GetUsers(c)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the clients list: %w", err)
+	}
+
+	var client unifi.User
+	found := false
+	for _, clt := range clients {
+		if clt.HwAddress.String() == hwAddr.String() {
+			client = clt
+			found = true
+		} else if clt.UseFixedIP && clt.FixedIP.Equal(ip) {
+			return nil, errors.NewAlreadyExists(nil, fmt.Sprintf("this IP address is already reserved for %s", clt.HwAddress))
+		}
+	}
+
+	if found {
+		client, err = s.unifi.UpdateUser(c, client.ID, update)
+	} else {
+		update.MAC = hwAddr.String()
+		client, err = s.unifi.CreateUser(c, update)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while saving the reservation: %w", err)
+	}
+
+	s.runTask(c)
+
+	return &reservationOut{
+		ID:        client.ID,
+		MAC:       hwAddr.String(),
+		Name:      in.Name,
+		IP:        ip.String(),
+		NetworkID: update.NetworkID,
+	}, nil
+}
+
+type staticDNSSyncIn struct {
+	Mode      string `query:"mode" validate:"required"`
+	DryRun    bool   `query:"dry_run"`
+	Overwrite bool   `query:"overwrite"`
+	Prune     bool   `query:"prune"`
+}
+
+func (s *Server) staticDNSSync(c *gin.Context, in *staticDNSSyncIn) (*staticDNSSyncResult, error) {
+	result, err := s.syncStaticDNS(c, staticDNSSyncOptions{
+		Mode:      in.Mode,
+		DryRun:    in.DryRun,
+		Overwrite: in.Overwrite,
+		Prune:     in.Prune,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while synchronizing the static DNS records: %w", err)
+	}
+
+	if !in.DryRun && in.Mode == staticDNSSyncPull && len(result.Changes) > 0 {
+		s.runTask(c)
+	}
+
+	return result, nil
+}
+
+type clientsAdoptIn struct {
+	Network string   `json:"network"`
+	Match   string   `json:"match"`
+	MACs    []string `json:"macs"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+func (s *Server) clientsAdopt(c *gin.Context, in *clientsAdoptIn) (*AdoptResult, error) {
+	result, err := Adopt(c, s.db, s.unifi, AdoptOptions{
+		Network: in.Network,
+		Match:   in.Match,
+		MACs:    in.MACs,
+		DryRun:  in.DryRun,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while adopting the clients: %w", err)
+	}
+
+	if !in.DryRun && slices.ContainsFunc(result.Entries, func(e AdoptEntry) bool { return e.Action == AdoptActionCreate }) {
+		s.runTask(c)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+	"text/template"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+const (
+	defaultHostnameTemplate = "{{.Name}}.{{.Domain}}"
+)
+
+var (
+	defaultTemplate = template.Must(template.New("default").Option("missingkey=error").Parse(defaultHostnameTemplate))
+)
+
+type hostnameData struct {
+	Name    string
+	Network string
+	Domain  string
+	IP      string
+	MAC     string
+}
+
+type networkSettings struct {
+	Domain   string
+	Template *template.Template
+	Aliases  []*template.Template
+}
+
+type networkSettingsConfig struct {
+	Network  string   `json:"network"`
+	Domain   string   `json:"domain"`
+	Template string   `json:"template"`
+	Aliases  []string `json:"aliases"`
+}
+
+func parseNetworkSettings(raw *networkSettingsConfig) (*networkSettings, error) {
+	settings := &networkSettings{
+		Domain: raw.Domain,
+	}
+
+	if raw.Template != "" {
+		tmpl, err := template.New(raw.Network).Option("missingkey=error").Parse(raw.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template %q: %w", raw.Template, err)
+		}
+		settings.Template = tmpl
+	}
+
+	for _, alias := range raw.Aliases {
+		tmpl, err := template.New(raw.Network).Option("missingkey=error").Parse(alias)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alias template %q: %w", alias, err)
+		}
+		settings.Aliases = append(settings.Aliases, tmpl)
+	}
+
+	return settings, nil
+}
+
+func renderHostname(tmpl *template.Template, data hostnameData) (string, error) {
+	buffer := bytes.NewBuffer(nil)
+	if err := tmpl.Execute(buffer, data); err != nil {
+		return "", fmt.Errorf("unable to execute the template: %w", err)
+	}
+
+	name := strings.TrimSpace(buffer.String())
+	if name == "" {
+		return "", fmt.Errorf("template %q produced an empty name", tmpl.Name())
+	}
+
+	return name, nil
+}
+
+// networkHostname returns the name unchanged when the network has neither a domain nor a template.
+func (cfg *config) networkHostname(network unifi.NetworkConf, name string, client unifi.User) (string, []string, error) {
+	data := hostnameData{
+		Name:    name,
+		Network: network.Name,
+		Domain:  cfg.networkDomain(network),
+		IP:      client.FixedIP.String(),
+		MAC:     client.HwAddress.String(),
+	}
+
+	tmpl := defaultTemplate
+	var aliasesTmpl []*template.Template
+
+	if settings, ok := cfg.Networks[network.Name]; ok {
+		if settings.Template != nil {
+			tmpl = settings.Template
+		}
+		aliasesTmpl = settings.Aliases
+	}
+
+	hostname := name
+	if data.Domain != "" || tmpl != defaultTemplate {
+		var err error
+		if hostname, err = renderHostname(tmpl, data); err != nil {
+			return "", nil, err
+		}
+		if err := db.ValidateName(hostname); err != nil {
+			return "", nil, fmt.Errorf("invalid hostname %q: %w", hostname, err)
+		}
+	}
+
+	aliases := make([]string, 0, len(aliasesTmpl))
+	for _, aliasTmpl := range aliasesTmpl {
+		alias, err := renderHostname(aliasTmpl, data)
+		if err != nil {
+			return "", nil, err
+		}
+		if err := db.ValidateName(alias); err != nil {
+			return "", nil, fmt.Errorf("invalid alias %q: %w", alias, err)
+		}
+		aliases = append(aliases, alias)
+	}
+
+	return hostname, aliases, nil
+}
+
+func (cfg *config) networkDomain(network unifi.NetworkConf) string {
+	if settings, ok := cfg.Networks[network.Name]; ok && settings.Domain != "" {
+		return settings.Domain
+	}
+	return network.DomainName
+}
+
+type networkEntry struct {
+	IP       net.IP
+	HostName string
+}
+
+func (cfg *config) networkEntries(network unifi.NetworkConf) ([]networkEntry, error) {
+	if network.IpSubnet == nil {
+		return nil, nil
+	}
+
+	data := hostnameData{
+		Network: network.Name,
+		Domain:  cfg.networkDomain(network),
+	}
+
+	entries := []networkEntry{}
+	for _, entry := range []struct {
+		tmpl *template.Template
+		ip   net.IP
+	}{
+		{tmpl: cfg.GatewayTemplate, ip: network.Gateway},
+		{tmpl: cfg.SubnetTemplate, ip: network.IpSubnet.IP},
+	} {
+		if entry.tmpl == nil || entry.ip == nil || entry.ip.To4() == nil {
+			continue
+		}
+
+		data.IP = entry.ip.String()
+		name, err := renderHostname(entry.tmpl, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := db.ValidateName(name); err != nil {
+			return nil, fmt.Errorf("invalid name %q for %s: %w", name, data.IP, err)
+		}
+
+		entries = append(entries, networkEntry{IP: entry.ip, HostName: name})
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+func Test_networkHostname(t *testing.T) {
+	iot, err := parseNetworkSettings(&networkSettingsConfig{
+		Network:  "IoT",
+		Template: "{{.Name}}-iot",
+		Aliases:  []string{"{{.Name}}.{{.Network}}.home.arpa"},
+	})
+	if err != nil {
+		t.Fatalf("parseNetworkSettings() error = %v", err)
+	}
+
+	lab, err := parseNetworkSettings(&networkSettingsConfig{
+		Network: "Lab",
+		Domain:  "lab.example.com",
+	})
+	if err != nil {
+		t.Fatalf("parseNetworkSettings() error = %v", err)
+	}
+
+	spaces, err := parseNetworkSettings(&networkSettingsConfig{
+		Network:  "Spaces",
+		Template: "{{.Name}} {{.Network}}",
+	})
+	if err != nil {
+		t.Fatalf("parseNetworkSettings() error = %v", err)
+	}
+
+	slash, err := parseNetworkSettings(&networkSettingsConfig{
+		Network: "Slash",
+		Aliases: []string{"{{.Name}}/{{.Network}}"},
+	})
+	if err != nil {
+		t.Fatalf("parseNetworkSettings() error = %v", err)
+	}
+
+	cfg := &config{
+		Networks: map[string]*networkSettings{
+			"IoT":    iot,
+			"Lab":    lab,
+			"Spaces": spaces,
+			"Slash":  slash,
+		},
+	}
+
+	client := unifi.User{
+		FixedIP: net.ParseIP("192.168.1.10"),
+	}
+
+	tests := []struct {
+		network     unifi.NetworkConf
+		wantName    string
+		wantAliases []string
+		wantErr     bool
+	}{
+		{network: unifi.NetworkConf{Name: "LAN"}, wantName: "foo", wantAliases: []string{}},
+		{network: unifi.NetworkConf{Name: "LAN", DomainName: "example.com"}, wantName: "foo.example.com", wantAliases: []string{}},
+		{network: unifi.NetworkConf{Name: "Lab"}, wantName: "foo.lab.example.com", wantAliases: []string{}},
+		{network: unifi.NetworkConf{Name: "Lab", DomainName: "example.com"}, wantName: "foo.lab.example.com", wantAliases: []string{}},
+		{network: unifi.NetworkConf{Name: "IoT"}, wantName: "foo-iot", wantAliases: []string{"foo.IoT.home.arpa"}},
+		{network: unifi.NetworkConf{Name: "Spaces"}, wantErr: true},
+		{network: unifi.NetworkConf{Name: "Slash"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.network.Name+"/"+tt.network.DomainName, func(t *testing.T) {
+			name, aliases, err := cfg.networkHostname(tt.network, "foo", client)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("networkHostname() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if name != tt.wantName {
+				t.Errorf("networkHostname() name = %v, want %v", name, tt.wantName)
+			}
+			if !reflect.DeepEqual(aliases, tt.wantAliases) {
+				t.Errorf("networkHostname() aliases = %v, want %v", aliases, tt.wantAliases)
+			}
+		})
+	}
+}
+
+func Test_clientName(t *testing.T) {
+	tests := []struct {
+		name   string
+		client unifi.User
+		want   string
+	}{
+		{name: "note", client: unifi.User{Name: "nas", HostName: "host", Note: "dns-name: files"}, want: "files"},
+		{name: "name", client: unifi.User{Name: "nas", HostName: "host"}, want: "nas"},
+		{name: "hostname", client: unifi.User{HostName: "host"}, want: "host"},
+		{name: "invalid note", client: unifi.User{Name: "nas", HostName: "host", Note: "dns-name: my files"}, want: "nas"},
+		{name: "invalid note without name", client: unifi.User{HostName: "host", Note: "dns-name: files!"}, want: "host"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientName(context.Background(), tt.client); got != tt.want {
+				t.Errorf("clientName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_reverseName(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "192.168.1.10", want: "10.1.168.192.in-addr.arpa"},
+		{ip: "::ffff:192.168.1.10", want: "10.1.168.192.in-addr.arpa"},
+		{ip: "fd00::10", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := reverseName(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("reverseName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"net"
+	"time"
+
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+const (
+	sourceUnifi    = "unifi"
+	sourceDatabase = "database"
+)
+
+type inventoryNetwork struct {
+	Name     string `json:"name"`
+	Purpose  string `json:"purpose"`
+	Subnet   string `json:"subnet,omitempty"`
+	Gateway  string `json:"gateway,omitempty"`
+	Domain   string `json:"domain,omitempty"`
+	Excluded bool   `json:"excluded"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+type inventoryClient struct {
+	MAC      string   `json:"mac"`
+	Name     string   `json:"name"`
+	IP       string   `json:"ip"`
+	Network  string   `json:"network,omitempty"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Excluded bool     `json:"excluded"`
+	Reason   string   `json:"reason,omitempty"`
+}
+
+type inventorySource struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+	Hosts    int    `json:"hosts"`
+	Error    string `json:"error,omitempty"`
+}
+
+type inventoryHost struct {
+	IP       string   `json:"ip"`
+	HostName string   `json:"hostname"`
+	Aliases  []string `json:"aliases,omitempty"`
+	Source   string   `json:"source"`
+}
+
+type ipActivity struct {
+	MAC      string
+	LastSeen time.Time
+}
+
+type inventory struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Networks    []inventoryNetwork `json:"networks"`
+	Clients     []inventoryClient  `json:"clients"`
+	Sources     []inventorySource  `json:"sources"`
+	Hosts       []inventoryHost    `json:"hosts"`
+
+	activity map[string]ipActivity
+}
+
+// seen records the activity of a client on its fixed and last IP addresses.
+func (inv *inventory) seen(client unifi.User) {
+	if client.LastSeen.IsZero() {
+		return
+	}
+
+	ips := []net.IP{client.LastIP}
+	if client.UseFixedIP {
+		ips = append(ips, client.FixedIP)
+	}
+
+	for _, ip := range ips {
+		if ip == nil {
+			continue
+		}
+
+		key := ip.String()
+		if activity, ok := inv.activity[key]; !ok || client.LastSeen.After(activity.LastSeen) {
+			inv.activity[key] = ipActivity{
+				MAC:      client.HwAddress.String(),
+				LastSeen: client.LastSeen,
+			}
+		}
+	}
+}
+
+func (s *Server) setInventory(inv *inventory) {
+	s.inventoryMut.Lock()
+	defer s.inventoryMut.Unlock()
+
+	s.inventory = inv
+}
+
+func (s *Server) getInventory() *inventory {
+	s.inventoryMut.Lock()
+	defer s.inventoryMut.Unlock()
+
+	return s.inventory
+}
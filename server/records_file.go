@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ghodss/yaml"
+	"github.com/sirupsen/logrus"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
+)
+
+const (
+	// recordsFileSource is the managed_by marker of the records of the RECORDS_FILE file.
+	recordsFileSource = "records-file"
+
+	recordsFilePollInterval = 10 * time.Second
+)
+
+type recordsFile struct {
+	Records []struct {
+		Name   string `json:"name"`
+		Target string `json:"target"`
+	} `json:"records"`
+}
+
+func loadRecordsFile(path string) ([]db.Record, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the records file: %w", err)
+	}
+
+	var content recordsFile
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("unable to parse the records file %s: %w", path, err)
+	}
+
+	records := make([]db.Record, 0, len(content.Records))
+	for _, entry := range content.Records {
+		records = append(records, db.Record{
+			Name:   entry.Name,
+			Target: entry.Target,
+		})
+	}
+
+	return records, nil
+}
+
+func (s *Server) reconcileRecordsFile(ctx context.Context) (bool, error) {
+	records, err := loadRecordsFile(s.cfg.RecordsFile)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = db.WithActor(ctx, db.Actor{Name: recordsFileSource})
+
+	result, err := s.db.ReconcileRecords(ctx, recordsFileSource, records)
+	if err != nil {
+		return false, fmt.Errorf("unable to reconcile the records of %s: %w", s.cfg.RecordsFile, err)
+	}
+
+	if len(result.Conflicts) > 0 {
+		logrus.WithContext(ctx).Warningf("records of %s already defined outside of the file: %s", s.cfg.RecordsFile, strings.Join(result.Conflicts, ", "))
+	}
+	if result.Changed() {
+		logrus.WithContext(ctx).Infof("records of %s reconciled: %d created, %d updated, %d deleted", s.cfg.RecordsFile, len(result.Created), len(result.Updated), len(result.Deleted))
+	}
+
+	return result.Changed(), nil
+}
+
+func (s *Server) watchRecordsFile(ctx context.Context) {
+	lastHash, _ := utils.FileHash(s.cfg.RecordsFile)
+
+	ticker := time.NewTicker(recordsFilePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			hash, err := utils.FileHash(s.cfg.RecordsFile)
+			if err != nil {
+				logrus.WithContext(ctx).WithError(err).Warningf("unable to read the records file %s", s.cfg.RecordsFile)
+				continue
+			}
+			if hash == lastHash {
+				continue
+			}
+
+			changed, err := s.reconcileRecordsFile(ctx)
+			if err != nil {
+				logrus.WithContext(ctx).WithError(err).Error("unable to reconcile the records file")
+				continue
+			}
+			lastHash = hash
+
+			if changed {
+				s.runTask(ctx)
+			}
+		}
+	}
+}
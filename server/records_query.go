@@ -0,0 +1,244 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/netip"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+const (
+	HeaderTotalCount = "X-Total-Count"
+	HeaderNextCursor = "X-Next-Cursor"
+
+	recordSortDefault = "name"
+)
+
+type recordSortKey struct {
+	value   func(db.Record) string
+	compare func(a, b string) int
+}
+
+var recordSortKeys = map[string]recordSortKey{
+	"name": {
+		value:   func(r db.Record) string { return strings.ToLower(r.Name) },
+		compare: strings.Compare,
+	},
+	"target": {
+		value:   func(r db.Record) string { return r.Target },
+		compare: compareAddresses,
+	},
+	"created_at": {
+		value:   func(r db.Record) string { return r.CreatedAt.UTC().Format(sortTimeFormat) },
+		compare: strings.Compare,
+	},
+	"updated_at": {
+		value:   func(r db.Record) string { return r.UpdatedAt.UTC().Format(sortTimeFormat) },
+		compare: strings.Compare,
+	},
+}
+
+// sortTimeFormat has a fixed width, so that the timestamps compare as strings.
+const sortTimeFormat = "2006-01-02T15:04:05.000000000Z07:00"
+
+func compareAddresses(a, b string) int {
+	addrA, errA := netip.ParseAddr(a)
+	addrB, errB := netip.ParseAddr(b)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+	return addrA.Compare(addrB)
+}
+
+type recordCursor struct {
+	Sort string `json:"s"`
+	Key  string `json:"k"`
+	ID   string `json:"i"`
+}
+
+func (c recordCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeRecordCursor(value string) (*recordCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, errors.NewBadRequest(err, "invalid cursor")
+	}
+
+	var cursor recordCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, errors.NewBadRequest(err, "invalid cursor")
+	}
+	return &cursor, nil
+}
+
+type recordQuery struct {
+	name     string
+	target   *netip.Prefix
+	selector db.Selector
+
+	createdAfter  time.Time
+	createdBefore time.Time
+
+	sort       string
+	key        recordSortKey
+	descending bool
+
+	limit  int
+	cursor *recordCursor
+}
+
+func newRecordQuery(in *recordListIn) (*recordQuery, error) {
+	q := &recordQuery{
+		name:  strings.ToLower(in.Name),
+		sort:  in.Sort,
+		limit: in.Limit,
+	}
+
+	if q.name != "" {
+		if _, err := path.Match(q.name, ""); err != nil {
+			return nil, errors.NewBadRequest(err, "invalid name pattern")
+		}
+	}
+
+	if in.Target != "" {
+		prefix, err := netip.ParsePrefix(in.Target)
+		if err != nil {
+			addr, errAddr := netip.ParseAddr(in.Target)
+			if errAddr != nil {
+				return nil, errors.NewBadRequest(err, "invalid target value")
+			}
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		prefix = prefix.Masked()
+		q.target = &prefix
+	}
+
+	selector, err := db.ParseSelector(in.Selector)
+	if err != nil {
+		return nil, err
+	}
+	q.selector = selector
+
+	if in.CreatedAfter != "" {
+		createdAfter, err := time.Parse(time.RFC3339, in.CreatedAfter)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, "invalid created_after value")
+		}
+		q.createdAfter = createdAfter
+	}
+
+	if in.CreatedBefore != "" {
+		createdBefore, err := time.Parse(time.RFC3339, in.CreatedBefore)
+		if err != nil {
+			return nil, errors.NewBadRequest(err, "invalid created_before value")
+		}
+		q.createdBefore = createdBefore
+	}
+
+	if q.sort == "" {
+		q.sort = recordSortDefault
+	}
+	key, ok := recordSortKeys[strings.TrimPrefix(q.sort, "-")]
+	if !ok {
+		return nil, errors.NewBadRequest(nil, "invalid sort value")
+	}
+	q.key = key
+	q.descending = strings.HasPrefix(q.sort, "-")
+
+	if q.limit < 0 {
+		return nil, errors.NewBadRequest(nil, "invalid limit value")
+	}
+
+	if in.Cursor != "" {
+		cursor, err := decodeRecordCursor(in.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if cursor.Sort != q.sort {
+			return nil, errors.NewBadRequest(nil, "the cursor does not match the sort order")
+		}
+		q.cursor = cursor
+	}
+
+	return q, nil
+}
+
+func (q *recordQuery) match(record db.Record) bool {
+	if q.name != "" {
+		if ok, _ := path.Match(q.name, strings.ToLower(record.Name)); !ok {
+			return false
+		}
+	}
+
+	if q.target != nil {
+		addr, err := netip.ParseAddr(record.Target)
+		if err != nil || !q.target.Contains(addr.Unmap()) {
+			return false
+		}
+	}
+
+	if !q.selector.Matches(record.Labels) {
+		return false
+	}
+
+	if !q.createdAfter.IsZero() && !record.CreatedAt.After(q.createdAfter) {
+		return false
+	}
+	if !q.createdBefore.IsZero() && !record.CreatedAt.Before(q.createdBefore) {
+		return false
+	}
+
+	return true
+}
+
+// compare breaks the ties by ID, to keep the order stable between the pages.
+func (q *recordQuery) compare(keyA, idA, keyB, idB string) int {
+	c := q.key.compare(keyA, keyB)
+	if c == 0 {
+		c = strings.Compare(idA, idB)
+	}
+	if q.descending {
+		c = -c
+	}
+	return c
+}
+
+func (q *recordQuery) apply(records []db.Record) ([]db.Record, int, string) {
+	matching := make([]db.Record, 0, len(records))
+	for _, record := range records {
+		if q.match(record) {
+			matching = append(matching, record)
+		}
+	}
+
+	sort.Slice(matching, func(i, j int) bool {
+		return q.compare(q.key.value(matching[i]), matching[i].ID, q.key.value(matching[j]), matching[j].ID) < 0
+	})
+
+	page := matching
+	if q.cursor != nil {
+		start := sort.Search(len(page), func(i int) bool {
+			return q.compare(q.key.value(page[i]), page[i].ID, q.cursor.Key, q.cursor.ID) > 0
+		})
+		page = page[start:]
+	}
+
+	next := ""
+	if q.limit > 0 && len(page) > q.limit {
+		page = page[:q.limit]
+		last := page[len(page)-1]
+		next = recordCursor{Sort: q.sort, Key: q.key.value(last), ID: last.ID}.encode()
+	}
+
+	return page, len(matching), next
+}
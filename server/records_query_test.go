@@ -0,0 +1,120 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+func Test_recordQuery(t *testing.T) {
+	records := []db.Record{
+		{Base: db.Base{ID: "1"}, Name: "ci-runner-2", Target: "10.0.0.20", Labels: map[string]string{"env": "ci", "team": "dev"}, CreatedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{Base: db.Base{ID: "2"}, Name: "nas", Target: "192.168.1.10", Labels: map[string]string{"env": "home"}, CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Base: db.Base{ID: "3"}, Name: "CI-runner-1", Target: "10.0.0.3", Labels: map[string]string{"env": "ci", "team": "net"}, CreatedAt: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)},
+		{Base: db.Base{ID: "4"}, Name: "ci-runner-3", Target: "10.0.1.5", CreatedAt: time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	tests := []struct {
+		name  string
+		in    recordListIn
+		want  []string
+		total int
+	}{
+		{name: "default", in: recordListIn{}, want: []string{"3", "1", "4", "2"}, total: 4},
+		{name: "glob", in: recordListIn{Name: "ci-*"}, want: []string{"3", "1", "4"}, total: 3},
+		{name: "cidr", in: recordListIn{Target: "10.0.0.0/24"}, want: []string{"3", "1"}, total: 2},
+		{name: "address", in: recordListIn{Target: "192.168.1.10"}, want: []string{"2"}, total: 1},
+		{name: "target sort", in: recordListIn{Sort: "target"}, want: []string{"3", "1", "4", "2"}, total: 4},
+		{name: "descending", in: recordListIn{Sort: "-name", Limit: 2}, want: []string{"2", "4"}, total: 4},
+		{name: "selector", in: recordListIn{Selector: "env=ci"}, want: []string{"3", "1"}, total: 2},
+		{name: "selector not equal", in: recordListIn{Selector: "env=ci,team!=net"}, want: []string{"1"}, total: 1},
+		{name: "selector exists", in: recordListIn{Selector: "env"}, want: []string{"3", "1", "2"}, total: 3},
+		{name: "selector not exists", in: recordListIn{Selector: "!env"}, want: []string{"4"}, total: 1},
+		{name: "created after", in: recordListIn{CreatedAfter: "2024-01-02T00:00:00Z"}, want: []string{"3", "4"}, total: 2},
+		{name: "created before", in: recordListIn{CreatedBefore: "2024-01-03T00:00:00Z"}, want: []string{"1", "2"}, total: 2},
+		{name: "created between", in: recordListIn{CreatedAfter: "2024-01-01T00:00:00Z", CreatedBefore: "2024-01-03T00:00:00Z"}, want: []string{"1"}, total: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := newRecordQuery(&tt.in)
+			if err != nil {
+				t.Fatalf("newRecordQuery() error = %v", err)
+			}
+
+			page, total, _ := q.apply(records)
+			if total != tt.total {
+				t.Errorf("apply() total = %d, want %d", total, tt.total)
+			}
+			if len(page) != len(tt.want) {
+				t.Fatalf("apply() = %+v, want the IDs %v", page, tt.want)
+			}
+			for i, record := range page {
+				if record.ID != tt.want[i] {
+					t.Errorf("apply()[%d] = %s, want %s", i, record.ID, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func Test_recordQuery_pages(t *testing.T) {
+	records := []db.Record{
+		{Base: db.Base{ID: "1"}, Name: "c", Target: "10.0.0.1"},
+		{Base: db.Base{ID: "2"}, Name: "a", Target: "10.0.0.2"},
+		{Base: db.Base{ID: "3"}, Name: "b", Target: "10.0.0.3"},
+	}
+
+	var names []string
+	in := recordListIn{Limit: 2}
+	for {
+		q, err := newRecordQuery(&in)
+		if err != nil {
+			t.Fatalf("newRecordQuery() error = %v", err)
+		}
+
+		page, _, next := q.apply(records)
+		for _, record := range page {
+			names = append(names, record.Name)
+		}
+		if next == "" {
+			break
+		}
+		in.Cursor = next
+	}
+
+	if len(names) != 3 || names[0] != "a" || names[1] != "b" || names[2] != "c" {
+		t.Errorf("pages = %v, want [a b c]", names)
+	}
+
+	if _, err := newRecordQuery(&recordListIn{Sort: "target", Cursor: in.Cursor}); err == nil {
+		t.Error("newRecordQuery() accepted a cursor of another sort order")
+	}
+}
+
+func Test_newRecordQuery_invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   recordListIn
+	}{
+		{name: "name pattern", in: recordListIn{Name: "ci-["}},
+		{name: "cidr", in: recordListIn{Target: "10.0.0.0/33"}},
+		{name: "target", in: recordListIn{Target: "nas"}},
+		{name: "selector", in: recordListIn{Selector: "=ci"}},
+		{name: "created after", in: recordListIn{CreatedAfter: "2024-01-01"}},
+		{name: "created before", in: recordListIn{CreatedBefore: "yesterday"}},
+		{name: "sort", in: recordListIn{Sort: "size"}},
+		{name: "limit", in: recordListIn{Limit: -1}},
+		{name: "cursor encoding", in: recordListIn{Cursor: "not a cursor!"}},
+		{name: "cursor content", in: recordListIn{Cursor: "bm90IGpzb24"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newRecordQuery(&tt.in); !errors.IsBadRequest(err) {
+				t.Errorf("newRecordQuery() error = %v, want a bad request", err)
+			}
+		})
+	}
+}
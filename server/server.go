@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/loopfz/gadgeto/tonic"
 	"github.com/sirupsen/logrus"
 	"github.com/wI2L/fizz"
 	"github.com/wI2L/fizz/openapi"
 
 	"github.com/rclsilver-org/usg-dns-api/db"
+	invsrc "github.com/rclsilver-org/usg-dns-api/inventory"
 	"github.com/rclsilver-org/usg-dns-api/unifi"
 )
 
@@ -36,6 +39,12 @@ func WithVersion(version string) func(opts *config) {
 	}
 }
 
+func WithSources(sources ...invsrc.Source) func(opts *config) {
+	return func(opts *config) {
+		opts.Sources = append(opts.Sources, sources...)
+	}
+}
+
 type Server struct {
 	cfg    *config
 	db     *db.Database
@@ -43,9 +52,16 @@ type Server struct {
 	router *fizz.Fizz
 
 	taskTrigger chan bool
+
+	inventoryMut sync.Mutex
+	inventory    *inventory
+
+	snapshotMut sync.Mutex
+	snapshot    *unifi.Snapshot
+	snapshotErr error
 }
 
-func NewServer(ctx context.Context, db *db.Database, unifi *unifi.Client, opts ...ServerOptions) (*Server, error) {
+func NewServer(ctx context.Context, database *db.Database, unifi *unifi.Client, opts ...ServerOptions) (*Server, error) {
 	// load the configuration
 	cfg, err := loadConfig()
 	if err != nil {
@@ -66,6 +82,9 @@ func NewServer(ctx context.Context, db *db.Database, unifi *unifi.Client, opts .
 	engine := gin.New()
 	engine.UseRawPath = true
 
+	// let the handlers use the gin context to reach the request context
+	engine.ContextWithFallback = true
+
 	infos := &openapi.Info{
 		Title:   cfg.Title,
 		Version: cfg.Version,
@@ -76,7 +95,7 @@ func NewServer(ctx context.Context, db *db.Database, unifi *unifi.Client, opts .
 
 	s := &Server{
 		cfg:         cfg,
-		db:          db,
+		db:          database,
 		router:      router,
 		unifi:       unifi,
 		taskTrigger: make(chan bool, 1),
@@ -88,22 +107,66 @@ func NewServer(ctx context.Context, db *db.Database, unifi *unifi.Client, opts .
 			fizz.Summary("Checks if the API is healthy"),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
 		}, tonic.Handler(s.monPing, http.StatusOK))
+		mon.GET("/status", []fizz.OperationOption{
+			fizz.Summary("Get the status of the synchronization with the unifi-controller"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.monStatus, http.StatusOK))
 	}
 
 	records := router.Group("/records", "records", "manage the records", s.AuthMiddleware())
 	{
 		records.GET("", []fizz.OperationOption{
 			fizz.Summary("Get the records list"),
+			fizz.Header(HeaderTotalCount, "Number of records matching the filters", int(0)),
+			fizz.Header(HeaderNextCursor, "Cursor of the next page, when there are more records", ""),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
 		}, tonic.Handler(s.recordList, http.StatusOK))
 		records.POST("", []fizz.OperationOption{
 			fizz.Summary("Create a new record"),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
 		}, tonic.Handler(s.recordAdd, http.StatusCreated))
+		records.DELETE("", []fizz.OperationOption{
+			fizz.Summary("Delete the records matching a label selector"),
+			fizz.Description("The selector must have a key=value or key requirement. The records managed by a declarative file are never deleted."),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordDeleteSelected, http.StatusOK))
+		records.POST("batch", []fizz.OperationOption{
+			fizz.Summary("Create, update and delete records as a whole"),
+			fizz.Description("Also served as POST /records:batch. When one of the operations fails, none of them is applied."),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordBatch, http.StatusOK))
+		records.GET("stale", []fizz.OperationOption{
+			fizz.Summary("Get the records whose target has not been seen recently"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordStale, http.StatusOK))
+		records.GET("deleted", []fizz.OperationOption{
+			fizz.Summary("Get the deleted records which can be restored"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordDeletedList, http.StatusOK))
+		records.GET("by-name/:name", []fizz.OperationOption{
+			fizz.Summary("Get a record by its name"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordGetByName, http.StatusOK))
+		records.PUT("by-name/:name", []fizz.OperationOption{
+			fizz.Summary("Create or replace a record by its name"),
+			fizz.Description("Omitted labels, description and flags keep their current value."),
+			fizz.Response(fmt.Sprint(http.StatusCreated), "Created", db.Record{}, nil, nil),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordPutByName, http.StatusOK))
+		records.DELETE("by-name/:name", []fizz.OperationOption{
+			fizz.Summary("Delete a record by its name"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordDeleteByName, http.StatusOK))
 		records.PUT(":record_id", []fizz.OperationOption{
 			fizz.Summary("Update an existing record"),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
 		}, tonic.Handler(s.recordUpdate, http.StatusOK))
+		records.PATCH(":record_id", []fizz.OperationOption{
+			fizz.Summary("Partially update an existing record"),
+			fizz.Description("The body is a JSON merge patch (application/merge-patch+json, or application/json): only the given fields are changed."),
+			fizz.Response(fmt.Sprint(http.StatusUnsupportedMediaType), "Unsupported Media Type", APIError{}, nil, nil),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, requireContentType(contentTypeMergePatch, binding.MIMEJSON), tonic.Handler(s.recordPatch, http.StatusOK))
 		records.DELETE(":record_id", []fizz.OperationOption{
 			fizz.Summary("Delete an existing record"),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
@@ -112,9 +175,64 @@ func NewServer(ctx context.Context, db *db.Database, unifi *unifi.Client, opts .
 			fizz.Summary("Get an existing record"),
 			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
 		}, tonic.Handler(s.recordGet, http.StatusOK))
+		records.GET(":record_id/history", []fizz.OperationOption{
+			fizz.Summary("Get the versions of a record"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordHistory, http.StatusOK))
+		records.POST(":record_id/revert", []fizz.OperationOption{
+			fizz.Summary("Revert a record to a previous version"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordRevert, http.StatusOK))
+		records.POST(":record_id/restore", []fizz.OperationOption{
+			fizz.Summary("Restore a deleted record"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.recordRestore, http.StatusOK))
+	}
+
+	if unifi != nil {
+		controller := router.Group("/unifi", "unifi", "manage the unifi-controller", s.AuthMiddleware())
+		{
+			controller.PUT("clients/:mac/reservation", []fizz.OperationOption{
+				fizz.Summary("Reserve an IP address for a client"),
+				fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+			}, tonic.Handler(s.reservationSet, http.StatusOK))
+			controller.POST("clients/adopt", []fizz.OperationOption{
+				fizz.Summary("Create records from the clients with a fixed IP address"),
+				fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+			}, tonic.Handler(s.clientsAdopt, http.StatusOK))
+			controller.POST("static-dns/sync", []fizz.OperationOption{
+				fizz.Summary("Synchronize the records with the static DNS entries of the controller"),
+				fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+			}, tonic.Handler(s.staticDNSSync, http.StatusOK))
+		}
+	}
+
+	inventory := router.Group("/inventory", "inventory", "inspect the generated inventory", s.AuthMiddleware())
+	{
+		inventory.GET("", []fizz.OperationOption{
+			fizz.Summary("Get the inventory of the last generation"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.inventoryGet, http.StatusOK))
+	}
+
+	audit := router.Group("/audit", "audit", "inspect the changes of the records", s.AuthMiddleware())
+	{
+		audit.GET("", []fizz.OperationOption{
+			fizz.Summary("Get the audited changes of the records"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.auditList, http.StatusOK))
+	}
+
+	export := router.Group("/export", "export", "export the records and the inventory", s.AuthMiddleware())
+	{
+		export.GET("", []fizz.OperationOption{
+			fizz.Summary("Export the records or the inventory of the last generation"),
+			fizz.Response(fmt.Sprint(http.StatusInternalServerError), "Server Error", APIError{}, nil, nil),
+		}, tonic.Handler(s.exportGet, http.StatusOK))
 	}
 
 	tonic.SetErrorHook(errorHook)
+	tonic.SetRenderHook(renderHook, "")
 
 	return s, nil
 }
@@ -153,15 +271,50 @@ func (s *Server) StartTaskScheduler(ctx context.Context) {
 			if err != nil {
 				logrus.WithContext(ctx).WithError(err).Error("unable to write the hosts file")
 			}
+
+			s.runStaticDNSSync(ctx)
 		}
 	}()
 
+	if s.cfg.RecordsFile != "" {
+		if _, err := s.reconcileRecordsFile(ctx); err != nil {
+			logrus.WithContext(ctx).WithError(err).Error("unable to reconcile the records file")
+		}
+		go s.watchRecordsFile(ctx)
+	}
+
+	for _, source := range s.cfg.Sources {
+		if watcher, ok := source.(invsrc.Watcher); ok {
+			go s.watchSource(ctx, source.Name(), watcher)
+		}
+	}
+
 	s.taskTrigger <- false
 }
 
+// watchSource triggers a generation on each change of the source, reconnecting after a failure.
+func (s *Server) watchSource(ctx context.Context, name string, watcher invsrc.Watcher) {
+	for {
+		err := watcher.Watch(ctx, func() {
+			logrus.WithContext(ctx).Debugf("change detected by the inventory source %q", name)
+			s.runTask(ctx)
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		logrus.WithContext(ctx).WithError(err).Warningf("unable to watch the inventory source %q", name)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(30 * time.Second):
+		}
+	}
+}
+
 func (s *Server) Serve(ctx context.Context) error {
 	endpoint := fmt.Sprintf("%s:%d", s.cfg.ListenHost, s.cfg.ListenPort)
-	srv := &http.Server{Addr: endpoint, Handler: withLogging(s.router)}
+	srv := &http.Server{Addr: endpoint, Handler: withLogging(withCustomMethods(s.router))}
 
 	go func() {
 		logrus.WithContext(ctx).Infof("starting the HTTP server on %s", endpoint)
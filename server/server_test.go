@@ -0,0 +1,29 @@
+package server
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ovh/configstore"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+// newTestServer returns a server on an empty database, and its master token.
+func newTestServer(t *testing.T) (*Server, string) {
+	configstore.InMemory(t.Name()).Add(configstore.NewItem("DB_PATH", filepath.Join(t.TempDir(), "db.json"), 1))
+	t.Cleanup(func() { configstore.UnregisterProvider(t.Name()) })
+
+	database, err := db.NewDatabase(context.Background())
+	if err != nil {
+		t.Fatalf("NewDatabase() error = %v", err)
+	}
+	token := database.GenerateMasterToken()
+
+	s, err := NewServer(context.Background(), database, nil)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	return s, token
+}
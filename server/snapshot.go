@@ -0,0 +1,114 @@
+package server
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+type unifiStatusValue string
+
+const (
+	unifiStatusOK       unifiStatusValue = "OK"
+	unifiStatusDegraded unifiStatusValue = "DEGRADED"
+	unifiStatusDown     unifiStatusValue = "DOWN"
+	unifiStatusPending  unifiStatusValue = "PENDING"
+	unifiStatusDisabled unifiStatusValue = "DISABLED"
+)
+
+type unifiStatus struct {
+	Status      unifiStatusValue `json:"status"`
+	FetchedAt   *time.Time       `json:"fetched_at,omitempty"`
+	SnapshotAge int64            `json:"snapshot_age,omitempty"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// unifiSnapshot returns the last known snapshot when the controller is unreachable.
+func (s *Server) unifiSnapshot(ctx context.Context) (*unifi.Snapshot, error) {
+	if s.unifi == nil {
+		return &unifi.Snapshot{FetchedAt: time.Now()}, nil
+	}
+
+	snapshot, err := s.unifi.Fetch(ctx)
+	if err == nil {
+		if err := snapshot.Save(s.cfg.UnifiSnapshotFile); err != nil {
+			logrus.WithContext(ctx).WithError(err).Warning("unable to persist the unifi snapshot")
+		}
+		s.setSnapshot(snapshot, nil)
+
+		return snapshot, nil
+	}
+
+	cached := s.getSnapshot()
+	if cached == nil {
+		cached, err = unifi.LoadSnapshot(s.cfg.UnifiSnapshotFile)
+		if err != nil && !os.IsNotExist(err) {
+			logrus.WithContext(ctx).WithError(err).Warning("unable to load the unifi snapshot")
+		}
+	}
+
+	if cached == nil {
+		s.setSnapshot(nil, err)
+		return nil, err
+	}
+
+	logrus.WithContext(ctx).WithError(err).Warningf("the unifi-controller is unreachable, using the snapshot of %s", cached.FetchedAt)
+	s.setSnapshot(cached, err)
+
+	return cached, nil
+}
+
+func (s *Server) setSnapshot(snapshot *unifi.Snapshot, err error) {
+	s.snapshotMut.Lock()
+	defer s.snapshotMut.Unlock()
+
+	if snapshot != nil {
+		s.snapshot = snapshot
+	}
+	s.snapshotErr = err
+}
+
+func (s *Server) getSnapshot() *unifi.Snapshot {
+	s.snapshotMut.Lock()
+	defer s.snapshotMut.Unlock()
+
+	return s.snapshot
+}
+
+func (s *Server) getUnifiStatus() unifiStatus {
+	if s.unifi == nil {
+		return unifiStatus{Status: unifiStatusDisabled}
+	}
+
+	s.snapshotMut.Lock()
+	defer s.snapshotMut.Unlock()
+
+	status := unifiStatus{
+		Status: unifiStatusOK,
+	}
+
+	if s.snapshot == nil && s.snapshotErr == nil {
+		status.Status = unifiStatusPending
+	}
+
+	if s.snapshot != nil {
+		fetchedAt := s.snapshot.FetchedAt
+		status.FetchedAt = &fetchedAt
+		status.SnapshotAge = int64(time.Since(fetchedAt).Seconds())
+	}
+
+	if s.snapshotErr != nil {
+		status.Error = s.snapshotErr.Error()
+		if s.snapshot != nil {
+			status.Status = unifiStatusDegraded
+		} else {
+			status.Status = unifiStatusDown
+		}
+	}
+
+	return status
+}
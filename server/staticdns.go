@@ -0,0 +1,349 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+const (
+	staticDNSSyncPush = "push"
+	staticDNSSyncPull = "pull"
+)
+
+type staticDNSSyncOptions struct {
+	Mode      string
+	DryRun    bool
+	Overwrite bool
+	Prune     bool
+}
+
+type staticDNSChange struct {
+	Action   string `json:"action"`
+	Name     string `json:"name"`
+	Target   string `json:"target"`
+	Previous string `json:"previous,omitempty"`
+}
+
+type staticDNSConflict struct {
+	Name             string `json:"name"`
+	DatabaseTarget   string `json:"database_target,omitempty"`
+	ControllerTarget string `json:"controller_target,omitempty"`
+	Reason           string `json:"reason"`
+}
+
+type staticDNSSyncResult struct {
+	Mode      string              `json:"mode"`
+	DryRun    bool                `json:"dry_run"`
+	Changes   []staticDNSChange   `json:"changes"`
+	Conflicts []staticDNSConflict `json:"conflicts"`
+}
+
+func validateStaticDNSSyncMode(mode string) error {
+	switch mode {
+	case staticDNSSyncPush, staticDNSSyncPull:
+		return nil
+	}
+	return errors.NewBadRequest(nil, fmt.Sprintf("invalid static DNS sync mode %q", mode))
+}
+
+func staticDNSRecordType(target string) string {
+	if addr, err := netip.ParseAddr(target); err == nil && addr.Is6() {
+		return "AAAA"
+	}
+	return "A"
+}
+
+func (s *Server) syncStaticDNS(ctx context.Context, opts staticDNSSyncOptions) (*staticDNSSyncResult, error) {
+	if err := validateStaticDNSSyncMode(opts.Mode); err != nil {
+		return nil, err
+	}
+
+	if err := s.unifi.Login(ctx); err != nil {
+		return nil, fmt.Errorf("unable to login to the unifi-controller API: %w", err)
+	}
+
+	entries, err := s.unifi.GetStaticDNSRecords(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the static DNS records: %w", err)
+	}
+
+	controller := map[string]unifi.StaticDNSRecord{}
+	for _, entry := range entries {
+		if entry.RecordType != "A" && entry.RecordType != "AAAA" {
+			continue
+		}
+		controller[strings.ToLower(entry.Key)] = entry
+	}
+
+	database := map[string]db.Record{}
+	for _, record := range s.db.GetRecords() {
+		database[strings.ToLower(record.Name)] = record
+	}
+
+	result := &staticDNSSyncResult{
+		Mode:      opts.Mode,
+		DryRun:    opts.DryRun,
+		Changes:   []staticDNSChange{},
+		Conflicts: []staticDNSConflict{},
+	}
+
+	if opts.Mode == staticDNSSyncPush {
+		err = s.pushStaticDNS(ctx, opts, database, controller, result)
+	} else {
+		err = s.pullStaticDNS(ctx, opts, database, controller, result)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Server) pushStaticDNS(ctx context.Context, opts staticDNSSyncOptions, database map[string]db.Record, controller map[string]unifi.StaticDNSRecord, result *staticDNSSyncResult) error {
+	for _, key := range sortedKeys(database) {
+		record := database[key]
+		entry, ok := controller[key]
+		if ok && entry.Value == record.Target && entry.Enabled == record.Enabled {
+			continue
+		}
+
+		// the disabled records are not created in the controller
+		if !ok && !record.Enabled {
+			continue
+		}
+
+		if ok && entry.Value != record.Target && !opts.Overwrite {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             record.Name,
+				DatabaseTarget:   record.Target,
+				ControllerTarget: entry.Value,
+				Reason:           "the controller entry has another target",
+			})
+			continue
+		}
+
+		change := staticDNSChange{
+			Action: "create",
+			Name:   record.Name,
+			Target: record.Target,
+		}
+		if ok {
+			change.Action = "update"
+			change.Previous = entry.Value
+		}
+		result.Changes = append(result.Changes, change)
+
+		if opts.DryRun {
+			continue
+		}
+
+		entry.Key = record.Name
+		entry.Value = record.Target
+		entry.RecordType = staticDNSRecordType(record.Target)
+		entry.Enabled = record.Enabled
+
+		var err error
+		if ok {
+			_, err = s.unifi.UpdateStaticDNSRecord(ctx, entry)
+		} else {
+			_, err = s.unifi.CreateStaticDNSRecord(ctx, entry)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %s the static DNS record %q: %w", change.Action, record.Name, err)
+		}
+	}
+
+	if !opts.Prune {
+		return nil
+	}
+
+	for _, key := range sortedKeys(controller) {
+		entry := controller[key]
+		if _, ok := database[key]; ok {
+			continue
+		}
+
+		result.Changes = append(result.Changes, staticDNSChange{
+			Action: "delete",
+			Name:   entry.Key,
+			Target: entry.Value,
+		})
+
+		if opts.DryRun {
+			continue
+		}
+
+		if err := s.unifi.DeleteStaticDNSRecord(ctx, entry.ID); err != nil {
+			return fmt.Errorf("unable to delete the static DNS record %q: %w", entry.Key, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) pullStaticDNS(ctx context.Context, opts staticDNSSyncOptions, database map[string]db.Record, controller map[string]unifi.StaticDNSRecord, result *staticDNSSyncResult) error {
+	for _, key := range sortedKeys(controller) {
+		entry := controller[key]
+		if !entry.Enabled {
+			continue
+		}
+
+		// the hosts file only holds the IPv4 addresses
+		if addr, err := netip.ParseAddr(entry.Value); entry.RecordType != "A" || err != nil || !addr.Is4() {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				ControllerTarget: entry.Value,
+				Reason:           fmt.Sprintf("unsupported %s entry, only the IPv4 addresses can be imported", entry.RecordType),
+			})
+			continue
+		}
+
+		if err := db.ValidateName(entry.Key); err != nil {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				ControllerTarget: entry.Value,
+				Reason:           err.Error(),
+			})
+			continue
+		}
+		if err := db.ValidateTarget(entry.Value); err != nil {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				ControllerTarget: entry.Value,
+				Reason:           err.Error(),
+			})
+			continue
+		}
+
+		record, ok := database[key]
+		if ok && record.Target == entry.Value {
+			continue
+		}
+
+		if ok && record.ManagedBy != "" {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				DatabaseTarget:   record.Target,
+				ControllerTarget: entry.Value,
+				Reason:           fmt.Sprintf("the database record is managed by %s", record.ManagedBy),
+			})
+			continue
+		}
+
+		if ok && record.Protected {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				DatabaseTarget:   record.Target,
+				ControllerTarget: entry.Value,
+				Reason:           "the database record is protected",
+			})
+			continue
+		}
+
+		if ok && !opts.Overwrite {
+			result.Conflicts = append(result.Conflicts, staticDNSConflict{
+				Name:             entry.Key,
+				DatabaseTarget:   record.Target,
+				ControllerTarget: entry.Value,
+				Reason:           "the database record has another target",
+			})
+			continue
+		}
+
+		change := staticDNSChange{
+			Action: "create",
+			Name:   entry.Key,
+			Target: entry.Value,
+		}
+		if ok {
+			change.Action = "update"
+			change.Previous = record.Target
+		}
+		result.Changes = append(result.Changes, change)
+
+		if opts.DryRun {
+			continue
+		}
+
+		var err error
+		if ok {
+			_, err = s.db.UpdateRecord(ctx, record.ID, record.Name, entry.Value, record.Version)
+		} else {
+			_, err = s.db.AddRecord(ctx, entry.Key, entry.Value)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to %s the record %q: %w", change.Action, entry.Key, err)
+		}
+	}
+
+	if !opts.Prune {
+		return nil
+	}
+
+	for _, key := range sortedKeys(database) {
+		record := database[key]
+		if _, ok := controller[key]; ok || record.ManagedBy != "" || record.Protected {
+			continue
+		}
+
+		result.Changes = append(result.Changes, staticDNSChange{
+			Action: "delete",
+			Name:   record.Name,
+			Target: record.Target,
+		})
+
+		if opts.DryRun {
+			continue
+		}
+
+		if err := s.db.DeleteRecord(ctx, record.ID, record.Version); err != nil {
+			return fmt.Errorf("unable to delete the record %q: %w", record.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Server) runStaticDNSSync(ctx context.Context) {
+	if s.cfg.StaticDNSSync == "" || s.unifi == nil {
+		return
+	}
+
+	ctx = db.WithActor(ctx, db.Actor{Name: "static-dns-sync"})
+
+	result, err := s.syncStaticDNS(ctx, staticDNSSyncOptions{Mode: s.cfg.StaticDNSSync})
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("unable to synchronize the static DNS records")
+		return
+	}
+
+	for _, conflict := range result.Conflicts {
+		logrus.WithContext(ctx).Warningf("static DNS conflict on %q: %s", conflict.Name, conflict.Reason)
+	}
+	if len(result.Changes) > 0 {
+		logrus.WithContext(ctx).Infof("%d static DNS changes applied (%s)", len(result.Changes), result.Mode)
+
+		// the pulled records are published by the next generation
+		if result.Mode == staticDNSSyncPull {
+			s.runTask(ctx)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+	"github.com/rclsilver-org/usg-dns-api/unifi"
+)
+
+func Test_pullStaticDNS(t *testing.T) {
+	database := map[string]db.Record{
+		"nas": {Name: "nas", Target: "192.168.1.10", Enabled: true},
+	}
+	controller := map[string]unifi.StaticDNSRecord{
+		"nas":     {Key: "nas", Value: "192.168.1.10", RecordType: "A", Enabled: true},
+		"printer": {Key: "printer", Value: "192.168.1.9", RecordType: "A", Enabled: true},
+		"camera":  {Key: "camera", Value: "fd00::10", RecordType: "AAAA", Enabled: true},
+		"mapped":  {Key: "mapped", Value: "fd00::11", RecordType: "A", Enabled: true},
+	}
+
+	s := &Server{}
+	result := &staticDNSSyncResult{Changes: []staticDNSChange{}, Conflicts: []staticDNSConflict{}}
+	if err := s.pullStaticDNS(context.Background(), staticDNSSyncOptions{Mode: staticDNSSyncPull, DryRun: true}, database, controller, result); err != nil {
+		t.Fatalf("pullStaticDNS() error = %v", err)
+	}
+
+	wantChanges := []staticDNSChange{
+		{Action: "create", Name: "printer", Target: "192.168.1.9"},
+	}
+	if !reflect.DeepEqual(result.Changes, wantChanges) {
+		t.Errorf("pullStaticDNS() changes = %+v, want %+v", result.Changes, wantChanges)
+	}
+
+	wantConflicts := []staticDNSConflict{
+		{Name: "camera", ControllerTarget: "fd00::10", Reason: "unsupported AAAA entry, only the IPv4 addresses can be imported"},
+		{Name: "mapped", ControllerTarget: "fd00::11", Reason: "unsupported A entry, only the IPv4 addresses can be imported"},
+	}
+	if !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("pullStaticDNS() conflicts = %+v, want %+v", result.Conflicts, wantConflicts)
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"slices"
 	"sort"
-	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/rclsilver-org/usg-dns-api/db"
 	"github.com/rclsilver-org/usg-dns-api/pkg/utils"
 	"github.com/rclsilver-org/usg-dns-api/unifi"
 )
@@ -19,76 +21,247 @@ import (
 func (s *Server) writeHostsFile(ctx context.Context, manual bool) error {
 	logrus.WithContext(ctx).Debugf("starting to write the hosts file (manual: %v)", manual)
 
-	if err := s.unifi.Login(ctx); err != nil {
-		return fmt.Errorf("unable to login to the unifi-controller API: %w", err)
+	snapshot, err := s.unifiSnapshot(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the state of the unifi-controller: %w", err)
 	}
 
 	// build the networks map
-	networks, err := s.unifi.GetNetworks(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to fetch the networks list: %w", err)
+	networks := snapshot.Networks
+	inv := &inventory{
+		GeneratedAt: time.Now(),
+		activity:    map[string]ipActivity{},
 	}
-	networksMap := map[*net.IPNet]unifi.NetworkConf{}
+
+	excludedNetworks := map[string]string{}
+	gatewayEntries := []networkEntry{}
 	for _, network := range networks {
 		if !network.Enabled {
 			continue
 		}
-		networksMap[network.IpSubnet] = network
+
+		entry := inventoryNetwork{
+			Name:    network.Name,
+			Purpose: network.Purpose,
+			Domain:  network.DomainName,
+		}
+		if network.IpSubnet != nil {
+			entry.Subnet = network.IpSubnet.String()
+		}
+		if network.Gateway != nil {
+			entry.Gateway = network.Gateway.String()
+		}
+
+		if reason := s.cfg.Filter.network(network); reason != "" {
+			logrus.WithContext(ctx).Debugf("network %q excluded: %s", network.Name, reason)
+			entry.Excluded = true
+			entry.Reason = reason
+			excludedNetworks[network.Name] = reason
+		} else {
+			entries, err := s.cfg.networkEntries(network)
+			if err != nil {
+				logrus.WithContext(ctx).WithError(err).Warningf("unable to build the entries of the network %q", network.Name)
+			}
+			gatewayEntries = append(gatewayEntries, entries...)
+		}
+
+		inv.Networks = append(inv.Networks, entry)
 	}
 
-	// build the client map
-	clients, err := s.unifi.GetUsers(ctx)
-	if err != nil {
-		return fmt.Errorf("unable to fetch the clients list: %w", err)
+	type fixedIPClient struct {
+		unifi.User
+
+		Name       string
+		Network    unifi.NetworkConf
+		HasNetwork bool
+		Aliases    []string
 	}
-	fixedIPs := []unifi.User{}
+
+	// build the client map
+	clients := snapshot.Users
+	fixedIPs := []fixedIPClient{}
 	for _, client := range clients {
-		if client.UseFixedIP {
-			fixedIPs = append(fixedIPs, client)
+		inv.seen(client)
+
+		if !client.UseFixedIP {
+			continue
+		}
+
+		network, hasNetwork := unifi.ClientNetwork(client, networks)
+
+		entry := inventoryClient{
+			MAC:  client.HwAddress.String(),
+			Name: clientName(ctx, client),
+			IP:   client.FixedIP.String(),
+		}
+		if hasNetwork {
+			entry.Network = network.Name
 		}
+
+		directives := client.DNSDirectives()
+
+		reason := s.cfg.Filter.client(client, entry.Name)
+		if _, excluded := excludedNetworks[network.Name]; reason == "" && hasNetwork && excluded {
+			reason = fmt.Sprintf("network %q excluded", network.Name)
+		}
+		if reason == "" && directives.Skip {
+			reason = "skipped by the client note"
+		}
+
+		if reason != "" {
+			logrus.WithContext(ctx).Debugf("client %s (%s) excluded: %s", entry.Name, entry.MAC, reason)
+			entry.Excluded = true
+			entry.Reason = reason
+		} else {
+			fixedIPs = append(fixedIPs, fixedIPClient{User: client, Name: entry.Name, Network: network, HasNetwork: hasNetwork, Aliases: directives.Aliases})
+			entry.Aliases = directives.Aliases
+		}
+
+		inv.Clients = append(inv.Clients, entry)
 	}
 
 	type result struct {
 		HostName string
 		Aliases  []string
 		Reverse  string
+		Source   string
+		Priority int
 	}
 
 	// init the result with the fixed IP addresses
 	results := map[string]*result{}
 	for _, client := range fixedIPs {
-		ipBytes := client.FixedIP.To4()
-		name := client.Name
-		if name == "" {
-			name = client.HostName
+		result := result{
+			HostName: client.Name,
+			Reverse:  reverseName(client.FixedIP),
+			Source:   sourceUnifi,
+			Priority: s.cfg.UnifiPriority,
 		}
 
-		result := result{
-			HostName: name,
-			Reverse:  fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ipBytes[3], ipBytes[2], ipBytes[1], ipBytes[0]),
+		if client.HasNetwork {
+			hostName, aliases, err := s.cfg.networkHostname(client.Network, result.HostName, client.User)
+			if err != nil {
+				logrus.WithContext(ctx).WithError(err).Warningf("unable to build the hostname of %s in the network %q", client.FixedIP, client.Network.Name)
+			} else {
+				if hostName != result.HostName {
+					result.Aliases = append(result.Aliases, result.HostName)
+					result.HostName = hostName
+				}
+				result.Aliases = append(result.Aliases, aliases...)
+			}
 		}
 
-		for cidr, net := range networksMap {
-			if net.DomainName != "" && cidr.Contains(client.FixedIP) {
-				result.Aliases = append(result.Aliases, result.HostName)
-				result.HostName += "." + net.DomainName
+		for _, alias := range client.Aliases {
+			if err := db.ValidateName(alias); err != nil {
+				logrus.WithContext(ctx).WithError(err).Warningf("invalid alias %q in the note of %s", alias, client.FixedIP)
+				continue
 			}
+			result.Aliases = append(result.Aliases, alias)
 		}
 
 		results[client.FixedIP.String()] = &result
 	}
 
+	// merge the hosts of the inventory sources
+	for _, source := range s.cfg.Sources {
+		entry := inventorySource{
+			Name:     source.Name(),
+			Priority: source.Priority(),
+		}
+
+		hosts, err := source.Hosts(ctx)
+		if err != nil {
+			logrus.WithContext(ctx).WithError(err).Warningf("unable to fetch the hosts of the inventory source %q", source.Name())
+			entry.Error = err.Error()
+			inv.Sources = append(inv.Sources, entry)
+			continue
+		}
+
+		for _, host := range hosts {
+			if host.IP.To4() == nil {
+				continue
+			}
+
+			if err := db.ValidateName(host.Name); err != nil {
+				logrus.WithContext(ctx).Debugf("host %s (%s) of the inventory source %q skipped: %s", host.Name, host.IP, source.Name(), err)
+				continue
+			}
+
+			if reason := s.cfg.Filter.host(host.MAC, host.Name); reason != "" {
+				logrus.WithContext(ctx).Debugf("host %s (%s) of the inventory source %q excluded: %s", host.Name, host.IP, source.Name(), reason)
+				continue
+			}
+
+			ip := host.IP.String()
+			existing, ok := results[ip]
+			if ok && existing.Priority >= source.Priority() {
+				continue
+			}
+
+			result := &result{
+				HostName: host.Name,
+				Reverse:  reverseName(host.IP),
+				Source:   source.Name(),
+				Priority: source.Priority(),
+			}
+
+			// keep the names of the overridden entry as aliases
+			if ok {
+				for _, name := range append([]string{existing.HostName}, existing.Aliases...) {
+					if name != result.HostName && !slices.Contains(result.Aliases, name) {
+						result.Aliases = append(result.Aliases, name)
+					}
+				}
+			}
+
+			results[ip] = result
+			entry.Hosts++
+		}
+
+		inv.Sources = append(inv.Sources, entry)
+	}
+
+	// update the result with the gateway and network addresses
+	for _, entry := range gatewayEntries {
+		ip := entry.IP.String()
+		if _, ok := results[ip]; ok {
+			results[ip].Aliases = append(results[ip].Aliases, entry.HostName)
+		} else {
+			results[ip] = &result{
+				HostName: entry.HostName,
+				Reverse:  reverseName(entry.IP),
+				Source:   sourceUnifi,
+			}
+		}
+	}
+
+	if s.cfg.StaleRecordsDisableAfter > 0 {
+		s.disableStaleRecords(ctx, inv)
+	}
+
 	// update the result with the records from the database
 	records := s.db.GetRecords()
 	for _, record := range records {
-		if _, ok := results[record.Target]; ok {
-			results[record.Target].Aliases = append(results[record.Target].Aliases, record.Name)
-		} else {
-			ipParts := strings.Split(record.Target, ".")
+		if !record.Enabled {
+			logrus.WithContext(ctx).Debugf("record %s (%s) skipped: disabled", record.Name, record.Target)
+			continue
+		}
+
+		// the hosts file only holds the IPv4 addresses
+		target := net.ParseIP(record.Target).To4()
+		if target == nil {
+			logrus.WithContext(ctx).Debugf("record %s (%s) skipped: not an IPv4 address", record.Name, record.Target)
+			continue
+		}
 
-			results[record.Target] = &result{
+		ip := target.String()
+		if _, ok := results[ip]; ok {
+			results[ip].Aliases = append(results[ip].Aliases, record.Name)
+		} else {
+			results[ip] = &result{
 				HostName: record.Name,
-				Reverse:  fmt.Sprintf("%s.%s.%s.%s.in-addr.arpa", ipParts[3], ipParts[2], ipParts[1], ipParts[0]),
+				Reverse:  reverseName(target),
+				Source:   sourceDatabase,
 			}
 		}
 	}
@@ -103,6 +276,17 @@ func (s *Server) writeHostsFile(ctx context.Context, manual bool) error {
 		return fmt.Errorf("unable to sort IPv4 addresses: %w", err)
 	}
 
+	for _, ip := range keys {
+		inv.Hosts = append(inv.Hosts, inventoryHost{
+			IP:       ip,
+			HostName: results[ip].HostName,
+			Aliases:  results[ip].Aliases,
+			Source:   results[ip].Source,
+		})
+	}
+
+	s.setInventory(inv)
+
 	buffer := bytes.NewBuffer(nil)
 	buffer.WriteString("# File generated by the usg-dns-api\n")
 	buffer.WriteString("# Do not manually edit\n")
@@ -143,6 +327,48 @@ func (s *Server) writeHostsFile(ctx context.Context, manual bool) error {
 	return nil
 }
 
+// disableStaleRecords leaves the managed and the protected records untouched.
+func (s *Server) disableStaleRecords(ctx context.Context, inv *inventory) {
+	ctx = db.WithActor(ctx, db.Actor{Name: "stale-records"})
+
+	enabled := false
+	for _, record := range s.db.GetRecords() {
+		activity, ok := inv.activity[record.Target]
+		if !ok || !record.Enabled || record.ManagedBy != "" || record.Protected || time.Since(activity.LastSeen) <= s.cfg.StaleRecordsDisableAfter {
+			continue
+		}
+
+		if _, err := s.db.PatchRecord(ctx, record.ID, db.RecordPatch{Enabled: &enabled}, record.Version); err != nil {
+			logrus.WithContext(ctx).WithError(err).Warningf("unable to disable the stale record %s", record.Name)
+			continue
+		}
+		logrus.WithContext(ctx).Infof("record %s (%s) disabled: target not seen since %s", record.Name, record.Target, activity.LastSeen)
+	}
+}
+
+// clientName prefers the name set in the note, then the name, then the hostname.
+func clientName(ctx context.Context, client unifi.User) string {
+	if name := client.DNSDirectives().Name; name != "" {
+		err := db.ValidateName(name)
+		if err == nil {
+			return name
+		}
+		logrus.WithContext(ctx).WithError(err).Warningf("invalid dns-name %q in the note of %s", name, client.FixedIP)
+	}
+	if client.Name != "" {
+		return client.Name
+	}
+	return client.HostName
+}
+
+func reverseName(ip net.IP) string {
+	ipBytes := ip.To4()
+	if ipBytes == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa", ipBytes[3], ipBytes[2], ipBytes[1], ipBytes[0])
+}
+
 func sortIPv4Addresses(ips []string) ([]string, error) {
 	// Convertir les adresses IPv4 en net.IP pour comparaison
 	parsedIPs := make([]net.IP, len(ips))
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/rclsilver-org/usg-dns-api/db"
+)
+
+func Test_disableStaleRecords(t *testing.T) {
+	s, _ := newTestServer(t)
+	s.cfg.StaleRecordsDisableAfter = 24 * time.Hour
+	ctx := context.Background()
+
+	stale, err := s.db.AddRecord(ctx, "stale", "192.168.1.10")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	protected, err := s.db.AddRecord(ctx, "protected", "192.168.1.11", db.WithProtected(true))
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	recent, err := s.db.AddRecord(ctx, "recent", "192.168.1.12")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+	unknown, err := s.db.AddRecord(ctx, "unknown", "192.168.1.13")
+	if err != nil {
+		t.Fatalf("AddRecord() error = %v", err)
+	}
+
+	inv := &inventory{activity: map[string]ipActivity{
+		"192.168.1.10": {LastSeen: time.Now().Add(-48 * time.Hour)},
+		"192.168.1.11": {LastSeen: time.Now().Add(-48 * time.Hour)},
+		"192.168.1.12": {LastSeen: time.Now().Add(-time.Hour)},
+	}}
+	s.disableStaleRecords(ctx, inv)
+
+	for _, tt := range []struct {
+		record  db.Record
+		enabled bool
+	}{
+		{record: stale, enabled: false},
+		{record: protected, enabled: true},
+		{record: recent, enabled: true},
+		{record: unknown, enabled: true},
+	} {
+		record, err := s.db.GetRecord(tt.record.ID)
+		if err != nil {
+			t.Fatalf("GetRecord() error = %v", err)
+		}
+		if record.Enabled != tt.enabled {
+			t.Errorf("record %s enabled = %v, want %v", record.Name, record.Enabled, tt.enabled)
+		}
+	}
+
+	// the change is audited with its own actor
+	history, err := s.db.GetRecordHistory(stale.ID)
+	if err != nil {
+		t.Fatalf("GetRecordHistory() error = %v", err)
+	}
+	if last := history[len(history)-1]; last.Actor != "stale-records" || last.Enabled {
+		t.Errorf("GetRecordHistory() last version = %+v", last)
+	}
+}
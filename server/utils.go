@@ -3,6 +3,7 @@ package server
 import (
 	"net"
 	"net/http"
+	"strings"
 )
 
 func getRemoteAddress(r *http.Request) string {
@@ -12,3 +13,16 @@ func getRemoteAddress(r *http.Request) string {
 	remote_address, _, _ := net.SplitHostPort(r.RemoteAddr)
 	return remote_address
 }
+
+// withCustomMethods serves /records:batch as /records/batch, as the router does
+// not support a colon inside a path segment.
+func withCustomMethods(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		slash := strings.LastIndex(r.URL.Path, "/")
+		if colon := strings.LastIndex(r.URL.Path, ":"); colon > slash && slash >= 0 {
+			r.URL.Path = r.URL.Path[:colon] + "/" + r.URL.Path[colon+1:]
+			r.URL.RawPath = ""
+		}
+		next.ServeHTTP(w, r)
+	})
+}
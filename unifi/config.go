@@ -1,6 +1,7 @@
 package unifi
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -14,8 +15,11 @@ const (
 	keyPassword = "UNIFI_PASSWORD"
 )
 
+var ErrNotConfigured = errors.New("no unifi-controller configured")
+
 type config struct {
 	Url      string
+	V2Url    string
 	Site     string
 	Username string
 	Password string
@@ -27,11 +31,12 @@ func loadConfig() (*config, error) {
 	url, err := configstore.GetItemValue(keyUrl)
 	if err != nil {
 		if _, ok := err.(configstore.ErrItemNotFound); ok {
-			err = fmt.Errorf("not found")
+			return nil, ErrNotConfigured
 		}
 		return nil, fmt.Errorf("unable to get the unifi URL: %w", err)
 	} else {
 		cfg.Url = strings.TrimSuffix(url, "/") + "/api"
+		cfg.V2Url = strings.TrimSuffix(url, "/") + "/v2/api"
 	}
 
 	site, err := configstore.GetItemValue(keySite)
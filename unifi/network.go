@@ -0,0 +1,50 @@
+package unifi
+
+import (
+	"sort"
+)
+
+// ClientNetwork looks the network up by ID, or picks the most specific enabled
+// network containing the fixed IP address, ties broken by name.
+func ClientNetwork(client User, networks []NetworkConf) (NetworkConf, bool) {
+	for _, id := range []string{client.FixedIPNetworkID, client.NetworkID} {
+		if id == "" {
+			continue
+		}
+
+		for _, network := range networks {
+			if network.ID == id {
+				return network, network.Enabled
+			}
+		}
+	}
+
+	if client.FixedIP == nil {
+		return NetworkConf{}, false
+	}
+
+	candidates := []NetworkConf{}
+	for _, network := range networks {
+		if network.Enabled && network.IpSubnet != nil && network.IpSubnet.Contains(client.FixedIP) {
+			candidates = append(candidates, network)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return NetworkConf{}, false
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		onesI, _ := candidates[i].IpSubnet.Mask.Size()
+		onesJ, _ := candidates[j].IpSubnet.Mask.Size()
+		if onesI != onesJ {
+			return onesI > onesJ
+		}
+		if candidates[i].Name != candidates[j].Name {
+			return candidates[i].Name < candidates[j].Name
+		}
+		return candidates[i].ID < candidates[j].ID
+	})
+
+	return candidates[0], true
+}
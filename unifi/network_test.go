@@ -0,0 +1,43 @@
+package unifi
+
+import (
+	"net"
+	"testing"
+)
+
+func TestClientNetwork(t *testing.T) {
+	_, lan, _ := net.ParseCIDR("192.168.1.0/24")
+	_, vpn, _ := net.ParseCIDR("192.168.0.0/16")
+
+	networks := []NetworkConf{
+		{ID: "vpn", Name: "VPN", Enabled: true, IpSubnet: vpn},
+		{ID: "lan-b", Name: "LAN B", Enabled: true, IpSubnet: lan},
+		{ID: "lan-a", Name: "LAN A", Enabled: true, IpSubnet: lan},
+		{ID: "old", Name: "Old", Enabled: false, IpSubnet: lan},
+	}
+
+	tests := []struct {
+		name   string
+		client User
+		want   string
+		wantOk bool
+	}{
+		{name: "fixed IP network ID", client: User{FixedIPNetworkID: "vpn", NetworkID: "lan-b", FixedIP: net.ParseIP("192.168.1.10")}, want: "VPN", wantOk: true},
+		{name: "network ID", client: User{NetworkID: "lan-b", FixedIP: net.ParseIP("192.168.1.10")}, want: "LAN B", wantOk: true},
+		{name: "disabled network", client: User{NetworkID: "old", FixedIP: net.ParseIP("192.168.1.10")}, wantOk: false},
+		{name: "most specific CIDR", client: User{FixedIP: net.ParseIP("192.168.1.10")}, want: "LAN A", wantOk: true},
+		{name: "unknown ID", client: User{NetworkID: "unknown", FixedIP: net.ParseIP("192.168.2.10")}, want: "VPN", wantOk: true},
+		{name: "no network", client: User{FixedIP: net.ParseIP("10.0.0.1")}, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ClientNetwork(tt.client, networks)
+			if ok != tt.wantOk {
+				t.Fatalf("ClientNetwork() ok = %v, want %v", ok, tt.wantOk)
+			}
+			if ok && got.Name != tt.want {
+				t.Errorf("ClientNetwork() = %v, want %v", got.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package unifi
+
+import (
+	"strings"
+)
+
+const (
+	directiveName  = "dns-name"
+	directiveAlias = "dns-alias"
+	directiveSkip  = "dns-skip"
+)
+
+// DNSDirectives are read from the note of a client:
+//
+//	dns-name: nas
+//	dns-alias: backup, files
+//	dns-skip
+type DNSDirectives struct {
+	Name    string
+	Aliases []string
+	Skip    bool
+}
+
+func ParseDNSDirectives(note string) DNSDirectives {
+	var directives DNSDirectives
+
+	for _, line := range strings.Split(note, "\n") {
+		key, value, _ := strings.Cut(strings.TrimSpace(line), ":")
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case directiveName:
+			directives.Name = value
+
+		case directiveAlias:
+			for _, alias := range strings.Split(value, ",") {
+				if alias = strings.TrimSpace(alias); alias != "" {
+					directives.Aliases = append(directives.Aliases, alias)
+				}
+			}
+
+		case directiveSkip:
+			directives.Skip = value == "" || strings.EqualFold(value, "true") || strings.EqualFold(value, "yes")
+		}
+	}
+
+	return directives
+}
+
+func (u User) DNSDirectives() DNSDirectives {
+	return ParseDNSDirectives(u.Note)
+}
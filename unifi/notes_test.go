@@ -0,0 +1,30 @@
+package unifi
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDNSDirectives(t *testing.T) {
+	tests := []struct {
+		name string
+		note string
+		want DNSDirectives
+	}{
+		{name: "empty", note: "", want: DNSDirectives{}},
+		{name: "free text", note: "bought in 2021\nlocated in the garage", want: DNSDirectives{}},
+		{name: "aliases", note: "NAS of the office\ndns-alias: nas, backup ,", want: DNSDirectives{Aliases: []string{"nas", "backup"}}},
+		{name: "multiple aliases lines", note: "dns-alias: nas\nDNS-Alias: backup", want: DNSDirectives{Aliases: []string{"nas", "backup"}}},
+		{name: "name", note: "dns-name: files", want: DNSDirectives{Name: "files"}},
+		{name: "skip", note: "dns-skip", want: DNSDirectives{Skip: true}},
+		{name: "skip true", note: "dns-skip: true", want: DNSDirectives{Skip: true}},
+		{name: "skip false", note: "dns-skip: false", want: DNSDirectives{Skip: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseDNSDirectives(tt.note); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseDNSDirectives() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package unifi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+type Snapshot struct {
+	FetchedAt time.Time     `json:"fetched_at"`
+	Networks  []NetworkConf `json:"networks"`
+	Users     []User        `json:"users"`
+}
+
+func (c *Client) Fetch(ctx context.Context) (*Snapshot, error) {
+	if err := c.Login(ctx); err != nil {
+		return nil, fmt.Errorf("unable to login to the unifi-controller API: %w", err)
+	}
+
+	networks, err := c.GetNetworks(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the networks list: %w", err)
+	}
+
+	users, err := c.GetUsers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch the clients list: %w", err)
+	}
+
+	return &Snapshot{
+		FetchedAt: time.Now(),
+		Networks:  networks,
+		Users:     users,
+	}, nil
+}
+
+func LoadSnapshot(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal the snapshot: %w", err)
+	}
+
+	return &snapshot, nil
+}
+
+func (s *Snapshot) Save(path string) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("unable to marshal the snapshot: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("unable to create the snapshot file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("unable to write the snapshot file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to write the snapshot file: %w", err)
+	}
+
+	if err := os.Rename(f.Name(), path); err != nil {
+		return fmt.Errorf("unable to replace the snapshot file: %w", err)
+	}
+
+	return nil
+}
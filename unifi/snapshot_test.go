@@ -0,0 +1,41 @@
+package unifi
+
+import (
+	"net"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	ip, subnet, _ := net.ParseCIDR("192.168.1.1/24")
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+
+	snapshot := &Snapshot{
+		FetchedAt: time.Unix(1700000000, 0).UTC(),
+		Networks: []NetworkConf{
+			{ID: "lan", Name: "LAN", Purpose: "corporate", Enabled: true, IpSubnet: subnet, Gateway: ip, DomainName: "example.com"},
+		},
+		Users: []User{
+			{ID: "nas", Name: "nas", UseFixedIP: true, FixedIP: net.ParseIP("192.168.1.10"), HwAddress: mac, NetworkID: "lan", LastSeen: time.Unix(1700000000, 0)},
+		},
+	}
+
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := snapshot.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := LoadSnapshot(path)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Networks, snapshot.Networks) {
+		t.Errorf("LoadSnapshot() networks = %+v, want %+v", got.Networks, snapshot.Networks)
+	}
+	if len(got.Users) != 1 || got.Users[0].HwAddress.String() != mac.String() || !got.Users[0].FixedIP.Equal(snapshot.Users[0].FixedIP) || !got.Users[0].LastSeen.Equal(snapshot.Users[0].LastSeen) {
+		t.Errorf("LoadSnapshot() users = %+v, want %+v", got.Users, snapshot.Users)
+	}
+}
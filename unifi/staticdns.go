@@ -0,0 +1,83 @@
+package unifi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+type StaticDNSRecord struct {
+	ID         string `json:"_id,omitempty"`
+	Key        string `json:"key"`
+	Value      string `json:"value"`
+	RecordType string `json:"record_type"`
+	Enabled    bool   `json:"enabled"`
+	TTL        int    `json:"ttl,omitempty"`
+}
+
+func (c *Client) GetStaticDNSRecords(ctx context.Context) ([]StaticDNSRecord, error) {
+	res, err := c.doV2(ctx, http.MethodGet, "/site/"+c.cfg.Site+"/static-dns", nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	var records []StaticDNSRecord
+	if err := unmarshal(res, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (c *Client) CreateStaticDNSRecord(ctx context.Context, record StaticDNSRecord) (StaticDNSRecord, error) {
+	res, err := c.doV2(ctx, http.MethodPost, "/site/"+c.cfg.Site+"/static-dns", nil, nil, record)
+	if err != nil {
+		return StaticDNSRecord{}, fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	return staticDNSResult(res)
+}
+
+func (c *Client) UpdateStaticDNSRecord(ctx context.Context, record StaticDNSRecord) (StaticDNSRecord, error) {
+	res, err := c.doV2(ctx, http.MethodPut, "/site/"+c.cfg.Site+"/static-dns/"+url.PathEscape(record.ID), nil, nil, record)
+	if err != nil {
+		return StaticDNSRecord{}, fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	return staticDNSResult(res)
+}
+
+func (c *Client) DeleteStaticDNSRecord(ctx context.Context, id string) error {
+	res, err := c.doV2(ctx, http.MethodDelete, "/site/"+c.cfg.Site+"/static-dns/"+url.PathEscape(id), nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func staticDNSResult(res *http.Response) (StaticDNSRecord, error) {
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(res.Body)
+		return StaticDNSRecord{}, fmt.Errorf("unexpected status code: %d (%s)", res.StatusCode, body)
+	}
+
+	var record StaticDNSRecord
+	if err := unmarshal(res, &record); err != nil {
+		return StaticDNSRecord{}, err
+	}
+	return record, nil
+}
@@ -4,20 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"time"
 )
 
 type result[T any] struct {
 	Meta struct {
-		Result string `json:"rc"`
+		Result  string `json:"rc"`
+		Message string `json:"msg"`
 	} `json:"meta"`
 
 	Data T `json:"data"`
 }
 
 type NetworkConf struct {
+	ID         string     `json:"_id"`
 	Name       string     `json:"name"`
+	Purpose    string     `json:"purpose"`
 	Enabled    bool       `json:"enabled"`
 	IpSubnet   *net.IPNet `json:"ip_subnet"`
+	Gateway    net.IP     `json:"-"`
 	DomainName string     `json:"domain_name"`
 }
 
@@ -36,23 +41,88 @@ func (n *NetworkConf) UnmarshalJSON(data []byte) error {
 	}
 
 	if temp.IpSubnet != "" {
-		_, ipnet, err := net.ParseCIDR(temp.IpSubnet)
+		ip, ipnet, err := net.ParseCIDR(temp.IpSubnet)
 		if err != nil {
 			return fmt.Errorf("invalid CIDR block: %w", err)
 		}
 		n.IpSubnet = ipnet
+		n.Gateway = ip
 	}
 
 	return nil
 }
 
+func (n NetworkConf) MarshalJSON() ([]byte, error) {
+	type alias NetworkConf
+
+	temp := &struct {
+		IpSubnet string `json:"ip_subnet,omitempty"`
+		*alias
+	}{
+		alias: (*alias)(&n),
+	}
+
+	if n.IpSubnet != nil {
+		if n.Gateway != nil {
+			ones, _ := n.IpSubnet.Mask.Size()
+			temp.IpSubnet = fmt.Sprintf("%s/%d", n.Gateway, ones)
+		} else {
+			temp.IpSubnet = n.IpSubnet.String()
+		}
+	}
+
+	return json.Marshal(temp)
+}
+
 type User struct {
-	Name       string           `json:"name"`
-	HostName   string           `json:"hostname"`
-	UseFixedIP bool             `json:"use_fixedip"`
-	FixedIP    net.IP           `json:"fixed_ip"`
-	LastIP     net.IP           `json:"last_ip"`
-	HwAddress  net.HardwareAddr `json:"mac"`
+	ID               string           `json:"_id"`
+	Name             string           `json:"name"`
+	HostName         string           `json:"hostname"`
+	UseFixedIP       bool             `json:"use_fixedip"`
+	FixedIP          net.IP           `json:"fixed_ip"`
+	LastIP           net.IP           `json:"last_ip"`
+	HwAddress        net.HardwareAddr `json:"mac"`
+	NetworkID        string           `json:"network_id"`
+	FixedIPNetworkID string           `json:"fixedip_network_id"`
+	Note             string           `json:"note"`
+	LastSeen         time.Time        `json:"last_seen"`
+}
+
+func (u User) MarshalJSON() ([]byte, error) {
+	type alias User
+
+	temp := &struct {
+		FixedIP   string `json:"fixed_ip,omitempty"`
+		LastIP    string `json:"last_ip,omitempty"`
+		HwAddress string `json:"mac,omitempty"`
+		LastSeen  int64  `json:"last_seen,omitempty"`
+		*alias
+	}{
+		alias: (*alias)(&u),
+	}
+
+	if u.FixedIP != nil {
+		temp.FixedIP = u.FixedIP.String()
+	}
+	if u.LastIP != nil {
+		temp.LastIP = u.LastIP.String()
+	}
+	if u.HwAddress != nil {
+		temp.HwAddress = u.HwAddress.String()
+	}
+	if !u.LastSeen.IsZero() {
+		temp.LastSeen = u.LastSeen.Unix()
+	}
+
+	return json.Marshal(temp)
+}
+
+type UserUpdate struct {
+	MAC        string `json:"mac,omitempty"`
+	Name       string `json:"name,omitempty"`
+	UseFixedIP bool   `json:"use_fixedip"`
+	FixedIP    string `json:"fixed_ip,omitempty"`
+	NetworkID  string `json:"network_id,omitempty"`
 }
 
 func (u *User) UnmarshalJSON(data []byte) error {
@@ -62,6 +132,7 @@ func (u *User) UnmarshalJSON(data []byte) error {
 		FixedIP   string `json:"fixed_ip"`
 		LastIP    string `json:"last_ip"`
 		HwAddress string `json:"mac"`
+		LastSeen  int64  `json:"last_seen"`
 		*alias
 	}{
 		alias: (*alias)(u),
@@ -87,6 +158,10 @@ func (u *User) UnmarshalJSON(data []byte) error {
 		u.LastIP = lastIP
 	}
 
+	if temp.LastSeen != 0 {
+		u.LastSeen = time.Unix(temp.LastSeen, 0)
+	}
+
 	if string(temp.HwAddress) != "" {
 		hwAddr, err := net.ParseMAC(temp.HwAddress)
 		if err != nil {
@@ -10,6 +10,7 @@ import (
 	"net/http/cookiejar"
 	"net/url"
 
+	"github.com/juju/errors"
 	"github.com/sirupsen/logrus"
 )
 
@@ -22,6 +23,9 @@ func NewClient(ctx context.Context) (*Client, error) {
 	// load the configuration
 	cfg, err := loadConfig()
 	if err != nil {
+		if err == ErrNotConfigured {
+			return nil, err
+		}
 		return nil, fmt.Errorf("unable to load the configuration: %w", err)
 	}
 	logrus.WithContext(ctx).Debug("loaded the unifi configuration")
@@ -43,7 +47,15 @@ func NewClient(ctx context.Context) (*Client, error) {
 }
 
 func (c *Client) do(ctx context.Context, method, uri string, headers http.Header, queryArgs map[string]string, body any) (*http.Response, error) {
-	parsedURL, err := url.Parse(c.cfg.Url + uri)
+	return c.request(ctx, c.cfg.Url+uri, method, headers, queryArgs, body)
+}
+
+func (c *Client) doV2(ctx context.Context, method, uri string, headers http.Header, queryArgs map[string]string, body any) (*http.Response, error) {
+	return c.request(ctx, c.cfg.V2Url+uri, method, headers, queryArgs, body)
+}
+
+func (c *Client) request(ctx context.Context, rawURL, method string, headers http.Header, queryArgs map[string]string, body any) (*http.Response, error) {
+	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
 		return nil, fmt.Errorf("unable to parse the URL: %w", err)
 	}
@@ -135,6 +147,45 @@ func (c *Client) GetUsers(ctx context.Context) ([]User, error) {
 	return result.Data, nil
 }
 
+func (c *Client) UpdateUser(ctx context.Context, id string, update UserUpdate) (User, error) {
+	res, err := c.do(ctx, http.MethodPut, "/s/"+c.cfg.Site+"/rest/user/"+url.PathEscape(id), nil, nil, update)
+	if err != nil {
+		return User{}, fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	return userResult(res)
+}
+
+func (c *Client) CreateUser(ctx context.Context, update UserUpdate) (User, error) {
+	res, err := c.do(ctx, http.MethodPost, "/s/"+c.cfg.Site+"/rest/user", nil, nil, update)
+	if err != nil {
+		return User{}, fmt.Errorf("unable to execute the query: %w", err)
+	}
+	defer res.Body.Close()
+
+	return userResult(res)
+}
+
+func userResult(res *http.Response) (User, error) {
+	var result result[[]User]
+	if err := unmarshal(res, &result); err != nil {
+		if res.StatusCode != http.StatusOK {
+			return User{}, fmt.Errorf("unexpected status code: %d", res.StatusCode)
+		}
+		return User{}, err
+	}
+
+	if result.Meta.Result != "ok" {
+		return User{}, errors.NewBadRequest(nil, fmt.Sprintf("the unifi-controller rejected the request: %s", result.Meta.Message))
+	}
+	if len(result.Data) == 0 {
+		return User{}, fmt.Errorf("the unifi-controller returned no client")
+	}
+
+	return result.Data[0], nil
+}
+
 func unmarshal(res *http.Response, ret any) error {
 	dataBytes, err := io.ReadAll(res.Body)
 	if err != nil {